@@ -10,4 +10,38 @@ const (
 	ClusterOffline ClusterConditionType = "Offline"
 	// ClusterConfigMalformed means the cluster's configuration may be malformed.
 	ClusterConfigMalformed ClusterConditionType = "ConfigMalformed"
+	// CompleteAPIEnablements means discovery succeeded for every API group
+	// version the cluster reports. It is False when one or more group
+	// versions could not be listed, e.g. because an aggregated APIService
+	// is down.
+	CompleteAPIEnablements ClusterConditionType = "CompleteAPIEnablements"
+)
+
+// PlacementMode describes how a federated object's replicas are distributed
+// across the clusters selected by a PropagationPolicy.
+type PlacementMode string
+
+const (
+	// PlacementModeDuplicate places a full copy of the template in every
+	// selected cluster.
+	PlacementModeDuplicate PlacementMode = "Duplicate"
+	// PlacementModeDivide splits the replica count across the selected
+	// clusters instead of duplicating it in each of them.
+	PlacementModeDivide PlacementMode = "Divide"
+)
+
+// PropagationStatus is the outcome of applying a federated object's template
+// to a single member cluster.
+type PropagationStatus string
+
+const (
+	// ClusterPropagationOK means the object was created or updated in the
+	// member cluster successfully.
+	ClusterPropagationOK PropagationStatus = "Applied"
+	// ClusterPropagationFailed means the last apply attempt in the member
+	// cluster errored out.
+	ClusterPropagationFailed PropagationStatus = "Failed"
+	// ClusterPropagationPending means the object has not been reconciled
+	// against the member cluster yet.
+	ClusterPropagationPending PropagationStatus = "Pending"
 )
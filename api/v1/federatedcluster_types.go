@@ -34,8 +34,87 @@ type FederatedClusterSpec struct {
 	APIEndpoint string               `json:"apiEndpoint"`
 	CABundle    []byte               `json:"caBundle,omitempty"`
 	SecretRef   LocalSecretReference `json:"secretRef"`
+
+	// AuthMode selects how SecretRef is interpreted to build credentials
+	// for the member cluster. Defaults to Token.
+	// +kubebuilder:default=Token
+	// +optional
+	AuthMode ClusterAuthMode `json:"authMode,omitempty"`
+
+	// Taints lists the taints currently applied to this cluster. Users may
+	// seed taints here directly; the controller also appends/removes its
+	// own condition-driven taints (see TaintKeyNotReady/TaintKeyUnreachable)
+	// as ClusterReady/ClusterOffline change, mirroring how the Kubernetes
+	// node controller manages Node.Spec.Taints.
+	// +optional
+	Taints []Taint `json:"taints,omitempty"`
 }
 
+// TaintEffect is the effect a Taint has on objects placed in a cluster that
+// do not tolerate it.
+type TaintEffect string
+
+const (
+	// TaintEffectNoSchedule prevents new federated objects from being
+	// placed in the cluster, without disturbing ones already there.
+	TaintEffectNoSchedule TaintEffect = "NoSchedule"
+	// TaintEffectPreferNoSchedule marks the cluster as one to avoid when an
+	// alternative is available. FederatedObjectReconciler does not act on
+	// it: a cluster carrying only this effect stays eligible for placement.
+	TaintEffectPreferNoSchedule TaintEffect = "PreferNoSchedule"
+	// TaintEffectNoExecute evicts existing federated objects from the
+	// cluster, in addition to preventing new placements.
+	TaintEffectNoExecute TaintEffect = "NoExecute"
+)
+
+const (
+	// TaintKeyNotReady is applied by FederatedClusterReconciler once the
+	// ClusterReady condition has been False for longer than its configured
+	// toleration window.
+	TaintKeyNotReady = "federation.example.com/not-ready"
+	// TaintKeyUnreachable is applied by FederatedClusterReconciler as soon
+	// as the ClusterOffline condition is True.
+	TaintKeyUnreachable = "federation.example.com/unreachable"
+)
+
+// Taint marks a cluster so that federated objects which don't tolerate it
+// are not placed there (or are evicted from it), in the same spirit as a
+// Kubernetes Node taint.
+type Taint struct {
+	// Key is the taint key, e.g. TaintKeyNotReady.
+	Key string `json:"key"`
+	// Value is an optional taint value.
+	// +optional
+	Value string `json:"value,omitempty"`
+	// Effect is one of NoSchedule, PreferNoSchedule or NoExecute.
+	Effect TaintEffect `json:"effect"`
+	// TimeAdded is when this taint was applied. Set automatically for
+	// controller-managed taints.
+	// +optional
+	TimeAdded *metav1.Time `json:"timeAdded,omitempty"`
+}
+
+// ClusterAuthMode is the way a FederatedCluster's secret is interpreted to
+// build a rest.Config for the member cluster.
+type ClusterAuthMode string
+
+const (
+	// ClusterAuthModeToken expects SecretRef to hold a bearer "token" (and
+	// optional CABundle on the spec). This is the original behavior.
+	ClusterAuthModeToken ClusterAuthMode = "Token"
+	// ClusterAuthModeKubeconfig expects SecretRef to hold a full
+	// kubeconfig under the "kubeconfig" key; its current context is used.
+	ClusterAuthModeKubeconfig ClusterAuthMode = "Kubeconfig"
+	// ClusterAuthModeServiceAccountBootstrap expects SecretRef to
+	// initially hold a cluster-admin kubeconfig (same shape as
+	// ClusterAuthModeKubeconfig). The controller uses it once to create a
+	// dedicated ServiceAccount and ClusterRoleBinding in the member
+	// cluster, waits for its token Secret, rewrites SecretRef to
+	// ClusterAuthModeToken form, and switches AuthMode to
+	// ClusterAuthModeToken.
+	ClusterAuthModeServiceAccountBootstrap ClusterAuthMode = "ServiceAccountBootstrap"
+)
+
 // The local secret with same namespace
 type LocalSecretReference struct {
 	Name string `json:"name"`
@@ -47,6 +126,94 @@ type FederatedClusterStatus struct {
 	// Important: Run "make" to regenerate code after modifying this file
 	// Condition set of cluster status
 	Conditions []ClusterCondition `json:"conditions"`
+
+	// APIEnablements lists the API group versions discovered on the
+	// cluster and the resources each one serves.
+	// +optional
+	APIEnablements []APIEnablement `json:"apiEnablements,omitempty"`
+
+	// NodeSummary counts the cluster's nodes by readiness and the number
+	// of namespaces present.
+	// +optional
+	NodeSummary *NodeSummary `json:"nodeSummary,omitempty"`
+
+	// Allocatable is the sum of each node's status.allocatable, keyed by
+	// resource name (cpu, memory, ephemeral-storage, pods, and any
+	// extended resources such as a GPU vendor's resource name).
+	// +optional
+	Allocatable apiv1.ResourceList `json:"allocatable,omitempty"`
+
+	// Capacity is the sum of each node's status.capacity, keyed the same
+	// way as Allocatable.
+	// +optional
+	Capacity apiv1.ResourceList `json:"capacity,omitempty"`
+
+	// KubernetesVersion is the member cluster's reported git version, as
+	// seen on one of its nodes' status.nodeInfo.
+	// +optional
+	KubernetesVersion string `json:"kubernetesVersion,omitempty"`
+
+	// Region is derived from the well-known topology.kubernetes.io/region
+	// node label, when present.
+	// +optional
+	Region string `json:"region,omitempty"`
+
+	// Zone is derived from the well-known topology.kubernetes.io/zone
+	// node label, when present.
+	// +optional
+	Zone string `json:"zone,omitempty"`
+
+	// Evictions records when each controller-managed taint (see
+	// Spec.Taints) was applied, so the propagation subsystem can tell how
+	// long a cluster has been tainted.
+	// +optional
+	Evictions []TaintEviction `json:"evictions,omitempty"`
+}
+
+// TaintEviction records when a controller-managed taint was applied to the
+// cluster.
+type TaintEviction struct {
+	// TaintKey is the key of the taint this eviction record is for.
+	TaintKey string `json:"taintKey"`
+	// Effect is the effect of the taint at the time it was applied.
+	Effect TaintEffect `json:"effect"`
+	// Timestamp is when the taint was applied.
+	Timestamp metav1.Time `json:"timestamp"`
+}
+
+// NodeSummary counts a member cluster's nodes by readiness.
+type NodeSummary struct {
+	// Ready is the number of nodes with a True Ready condition.
+	Ready int32 `json:"ready"`
+	// NotReady is the number of nodes without a True Ready condition.
+	NotReady int32 `json:"notReady"`
+	// NamespaceCount is the total number of namespaces in the cluster.
+	NamespaceCount int32 `json:"namespaceCount"`
+}
+
+// APIEnablement is the set of resources served by a single discovered API
+// group version on a member cluster.
+type APIEnablement struct {
+	// GroupVersion is the discovered group/version, e.g. "apps/v1".
+	GroupVersion string `json:"groupVersion"`
+	// Resources lists the resources served under GroupVersion.
+	Resources []APIResource `json:"resources"`
+}
+
+// APIResource describes a single resource served under an APIEnablement's
+// GroupVersion.
+type APIResource struct {
+	// Kind is the resource's Kind, e.g. "Deployment".
+	Kind string `json:"kind"`
+	// Name is the resource's plural name as served by the API, e.g.
+	// "deployments". Combined with the owning APIEnablement's GroupVersion,
+	// this is the real Kind->GVR mapping the propagation subsystem uses to
+	// apply a template, instead of guessing a plural from Kind.
+	Name string `json:"name"`
+	// Namespaced is true if the resource is namespace-scoped.
+	Namespaced bool `json:"namespaced"`
+	// Verbs lists the supported verbs, e.g. ["get","list","watch"].
+	Verbs []string `json:"verbs"`
 }
 
 // ClusterCondition describes current state of a cluster.
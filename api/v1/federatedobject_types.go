@@ -0,0 +1,181 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"github.com/cloudfunny/kubefederation/api/v1/common"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
+// NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
+
+// FederatedObjectSpec defines the desired state of FederatedObject
+type FederatedObjectSpec struct {
+	// Template is the full manifest of the object to propagate to member
+	// clusters, as it would be submitted to a single cluster's API server.
+	Template runtime.RawExtension `json:"template"`
+
+	// Placement selects which member clusters the template is propagated
+	// to, either directly or through a PropagationPolicy.
+	Placement Placement `json:"placement"`
+
+	// Overrides lists the per-cluster JSON-patch operations to apply to
+	// Template before it is created/updated in a given member cluster.
+	// +optional
+	Overrides []ClusterOverride `json:"overrides,omitempty"`
+}
+
+// Placement describes the set of clusters a federated object is propagated
+// to.
+type Placement struct {
+	// Clusters explicitly lists the target clusters by name.
+	// +optional
+	Clusters []ClusterReference `json:"clusters,omitempty"`
+
+	// ClusterSelector selects target clusters by label, as an alternative
+	// to listing them explicitly in Clusters.
+	// +optional
+	ClusterSelector *metav1.LabelSelector `json:"clusterSelector,omitempty"`
+
+	// PolicyRef names a PropagationPolicy that computes the target
+	// clusters and placement mode. When set, it is combined with
+	// Clusters/ClusterSelector.
+	// +optional
+	PolicyRef *LocalPolicyReference `json:"policyRef,omitempty"`
+
+	// Tolerations allows this object to be placed in (or to remain in) a
+	// cluster carrying a matching Taint, instead of being skipped/evicted
+	// by it.
+	// +optional
+	Tolerations []Toleration `json:"tolerations,omitempty"`
+}
+
+// Toleration matches a Taint a cluster carries, in the same spirit as a
+// Kubernetes pod Toleration.
+type Toleration struct {
+	// Key is the taint key to match. Empty matches any key (Operator must
+	// be Exists in that case).
+	// +optional
+	Key string `json:"key,omitempty"`
+	// Operator is Equal (match Key and Value) or Exists (match Key alone).
+	// Defaults to Equal.
+	// +kubebuilder:default=Equal
+	// +optional
+	Operator TolerationOperator `json:"operator,omitempty"`
+	// Value is the taint value to match when Operator is Equal.
+	// +optional
+	Value string `json:"value,omitempty"`
+	// Effect matches a taint's Effect. Empty matches all effects.
+	// +optional
+	Effect TaintEffect `json:"effect,omitempty"`
+}
+
+// TolerationOperator is the relation a Toleration's Key/Value have to a
+// Taint's Key/Value.
+type TolerationOperator string
+
+const (
+	TolerationOpExists TolerationOperator = "Exists"
+	TolerationOpEqual  TolerationOperator = "Equal"
+)
+
+// ClusterReference identifies a target member cluster by name.
+type ClusterReference struct {
+	Name string `json:"name"`
+}
+
+// LocalPolicyReference names a PropagationPolicy in the FederatedObject's own
+// namespace. There is no cluster-scoped policy kind to refer to.
+type LocalPolicyReference struct {
+	Name string `json:"name"`
+}
+
+// ClusterOverride lists the patch operations to apply to the template
+// before it is propagated to a specific cluster.
+type ClusterOverride struct {
+	// ClusterName is the member cluster these patches apply to.
+	ClusterName string `json:"clusterName"`
+
+	// Patches is a list of JSON-patch style operations (op/path/value)
+	// applied to Template in order.
+	Patches []OverridePatch `json:"patches"`
+}
+
+// OverridePatch is a single JSON-patch style operation (RFC 6902), e.g.
+// {"op": "replace", "path": "/spec/replicas", "value": 3}.
+type OverridePatch struct {
+	// Op is one of "add", "remove" or "replace".
+	Op string `json:"op"`
+	// Path is the JSON pointer to the field being patched.
+	Path string `json:"path"`
+	// Value is the new value for "add"/"replace" operations. Unused for
+	// "remove".
+	// +optional
+	Value *apiextensionsv1.JSON `json:"value,omitempty"`
+}
+
+// FederatedObjectStatus defines the observed state of FederatedObject
+type FederatedObjectStatus struct {
+	// Clusters reports the per-cluster outcome of the last propagation
+	// attempt.
+	// +optional
+	Clusters []PropagatedClusterStatus `json:"clusters,omitempty"`
+}
+
+// PropagatedClusterStatus is the result of propagating a federated object's
+// template to a single member cluster.
+type PropagatedClusterStatus struct {
+	// Cluster is the name of the FederatedCluster this status is for.
+	Cluster string `json:"cluster"`
+	// Status is one of Applied, Failed or Pending.
+	Status common.PropagationStatus `json:"status"`
+	// LastUpdateTime is when Status was last observed.
+	LastUpdateTime metav1.Time `json:"lastUpdateTime,omitempty"`
+	// Reason is a brief explanation when Status is Failed.
+	// +optional
+	Reason *string `json:"reason,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:resource:shortName=fedobj
+
+// FederatedObject is the Schema for the federatedobjects API. It propagates
+// a namespaced object to the member clusters selected by its Placement.
+type FederatedObject struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   FederatedObjectSpec   `json:"spec,omitempty"`
+	Status FederatedObjectStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// FederatedObjectList contains a list of FederatedObject
+type FederatedObjectList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []FederatedObject `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&FederatedObject{}, &FederatedObjectList{})
+}
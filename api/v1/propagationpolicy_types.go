@@ -0,0 +1,98 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"github.com/cloudfunny/kubefederation/api/v1/common"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
+// NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
+
+// PropagationPolicySpec defines the cluster-selection and placement rules a
+// FederatedObject can refer to via Placement.PolicyRef.
+type PropagationPolicySpec struct {
+	// ClusterSelector selects target clusters by label.
+	// +optional
+	ClusterSelector *metav1.LabelSelector `json:"clusterSelector,omitempty"`
+
+	// ClusterAffinity further restricts the clusters selected by
+	// ClusterSelector: every term is required, and a cluster matches a term
+	// when its label Key has one of the term's Values.
+	// +optional
+	ClusterAffinity []ClusterAffinityTerm `json:"clusterAffinity,omitempty"`
+
+	// PlacementMode is Duplicate (the default) to put a full copy of the
+	// template in every selected cluster, or Divide to split the template's
+	// spec.replicas across them, each cluster's share clamped to
+	// MinReplicas/MaxReplicas.
+	// +kubebuilder:default=Duplicate
+	// +optional
+	PlacementMode common.PlacementMode `json:"placementMode,omitempty"`
+
+	// MinReplicas is the minimum number of replicas placed in a single
+	// selected cluster when PlacementMode is Divide.
+	// +optional
+	MinReplicas *int32 `json:"minReplicas,omitempty"`
+
+	// MaxReplicas is the maximum number of replicas placed in a single
+	// selected cluster when PlacementMode is Divide.
+	// +optional
+	MaxReplicas *int32 `json:"maxReplicas,omitempty"`
+}
+
+// ClusterAffinityTerm restricts cluster selection to clusters carrying a
+// given label value.
+type ClusterAffinityTerm struct {
+	// Key is the cluster label key to match.
+	Key string `json:"key"`
+	// Values is the set of acceptable values for Key.
+	Values []string `json:"values"`
+}
+
+// PropagationPolicyStatus defines the observed state of PropagationPolicy
+type PropagationPolicyStatus struct {
+	// ObservedGeneration is the generation most recently acted on.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+
+// PropagationPolicy is the Schema for the propagationpolicies API
+type PropagationPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   PropagationPolicySpec   `json:"spec,omitempty"`
+	Status PropagationPolicyStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// PropagationPolicyList contains a list of PropagationPolicy
+type PropagationPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []PropagationPolicy `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&PropagationPolicy{}, &PropagationPolicyList{})
+}
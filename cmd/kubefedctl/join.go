@@ -0,0 +1,89 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/pkg/errors"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/clientcmd"
+
+	federationv1 "github.com/cloudfunny/kubefederation/api/v1"
+)
+
+// runJoin registers a target cluster with the host cluster: it creates a
+// Secret holding the target cluster's kubeconfig and a FederatedCluster
+// pointing at it, mirroring upstream kubefed's kubefedctl join command.
+func runJoin(args []string) error {
+	fs := flag.NewFlagSet("join", flag.ExitOnError)
+	clusterName := fs.String("cluster-name", "", "name of the FederatedCluster to create (required)")
+	clusterContext := fs.String("cluster-context", "", "context (in --kubeconfig) of the cluster to join (defaults to --cluster-name)")
+	hostContext := fs.String("host-context", "", "context (in --kubeconfig) of the host cluster (defaults to the current context)")
+	hostNamespace := fs.String("host-namespace", "default", "namespace in the host cluster to create the FederatedCluster and Secret in")
+	kubeconfigPath := fs.String("kubeconfig", clientcmd.RecommendedHomeFile, "path to the kubeconfig holding both the host and target cluster contexts")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *clusterName == "" {
+		return errors.New("--cluster-name is required")
+	}
+
+	targetContext := *clusterContext
+	if targetContext == "" {
+		targetContext = *clusterName
+	}
+
+	targetKubeconfig, apiEndpoint, err := kubeconfigForContext(*kubeconfigPath, targetContext)
+	if err != nil {
+		return errors.Wrapf(err, "failed to load target cluster context %q", targetContext)
+	}
+
+	hostClient, err := hostClientFor(*kubeconfigPath, *hostContext)
+	if err != nil {
+		return errors.Wrap(err, "failed to build host cluster client")
+	}
+
+	ctx := context.Background()
+	secretName := *clusterName + "-secret"
+	secret := &apiv1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: secretName, Namespace: *hostNamespace},
+		Data:       map[string][]byte{kubeconfigSecretKey: targetKubeconfig},
+	}
+	if err := hostClient.Create(ctx, secret); err != nil {
+		return errors.Wrapf(err, "failed to create secret %s/%s", *hostNamespace, secretName)
+	}
+
+	fedCluster := &federationv1.FederatedCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: *clusterName, Namespace: *hostNamespace},
+		Spec: federationv1.FederatedClusterSpec{
+			APIEndpoint: apiEndpoint,
+			SecretRef:   federationv1.LocalSecretReference{Name: secretName},
+			AuthMode:    federationv1.ClusterAuthModeKubeconfig,
+		},
+	}
+	if err := hostClient.Create(ctx, fedCluster); err != nil {
+		return errors.Wrapf(err, "failed to create federatedcluster %s/%s", *hostNamespace, *clusterName)
+	}
+
+	fmt.Fprintf(os.Stdout, "cluster %q joined in namespace %q\n", *clusterName, *hostNamespace)
+	return nil
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"github.com/pkg/errors"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	federationv1 "github.com/cloudfunny/kubefederation/api/v1"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+)
+
+// kubeconfigSecretKey matches controllers.KubeconfigKey, the Secret data key
+// the FederatedCluster controller reads for AuthMode: Kubeconfig.
+const kubeconfigSecretKey = "kubeconfig"
+
+// kubeconfigForContext loads kubeconfigPath and returns a minimal kubeconfig
+// (as bytes, suitable for a Secret's "kubeconfig" key) scoped to the given
+// context, along with the API endpoint that context points at.
+func kubeconfigForContext(kubeconfigPath, contextName string) ([]byte, string, error) {
+	rawConfig, err := clientcmd.LoadFromFile(kubeconfigPath)
+	if err != nil {
+		return nil, "", errors.Wrapf(err, "failed to load %s", kubeconfigPath)
+	}
+
+	contextConfig, err := clientcmd.NewNonInteractiveClientConfig(*rawConfig, contextName, &clientcmd.ConfigOverrides{}, nil).ClientConfig()
+	if err != nil {
+		return nil, "", errors.Wrapf(err, "failed to resolve context %q", contextName)
+	}
+
+	rawContext, ok := rawConfig.Contexts[contextName]
+	if !ok {
+		return nil, "", errors.Errorf("context %q not found in %s", contextName, kubeconfigPath)
+	}
+
+	// Only the single target context (and the cluster/user it references) is
+	// kept, so the Secret this ends up in doesn't also carry every other
+	// cluster/user in kubeconfigPath — notably the host cluster's own
+	// credentials, since join loads both from the same file.
+	scoped := clientcmdapi.NewConfig()
+	scoped.CurrentContext = contextName
+	scoped.Contexts[contextName] = rawContext.DeepCopy()
+	if cluster, ok := rawConfig.Clusters[rawContext.Cluster]; ok {
+		scoped.Clusters[rawContext.Cluster] = cluster.DeepCopy()
+	}
+	if authInfo, ok := rawConfig.AuthInfos[rawContext.AuthInfo]; ok {
+		scoped.AuthInfos[rawContext.AuthInfo] = authInfo.DeepCopy()
+	}
+
+	scopedBytes, err := clientcmd.Write(*scoped)
+	if err != nil {
+		return nil, "", errors.Wrapf(err, "failed to serialize kubeconfig for context %q", contextName)
+	}
+
+	return scopedBytes, contextConfig.Host, nil
+}
+
+// hostClientFor builds a controller-runtime client for the host cluster
+// from kubeconfigPath, using hostContext when non-empty or the file's
+// current context otherwise.
+func hostClientFor(kubeconfigPath, hostContext string) (client.Client, error) {
+	overrides := &clientcmd.ConfigOverrides{}
+	if hostContext != "" {
+		overrides.CurrentContext = hostContext
+	}
+	restConfig, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		&clientcmd.ClientConfigLoadingRules{ExplicitPath: kubeconfigPath},
+		overrides,
+	).ClientConfig()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build host cluster rest config")
+	}
+
+	scheme := clientgoscheme.Scheme
+	if err := federationv1.AddToScheme(scheme); err != nil {
+		return nil, errors.Wrap(err, "failed to register federation types")
+	}
+
+	return client.New(restConfig, client.Options{Scheme: scheme})
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/pkg/errors"
+	apiv1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	federationv1 "github.com/cloudfunny/kubefederation/api/v1"
+)
+
+// runUnjoin removes a previously joined cluster: it deletes the
+// FederatedCluster and its referenced Secret from the host cluster.
+func runUnjoin(args []string) error {
+	fs := flag.NewFlagSet("unjoin", flag.ExitOnError)
+	clusterName := fs.String("cluster-name", "", "name of the FederatedCluster to remove (required)")
+	hostContext := fs.String("host-context", "", "context (in --kubeconfig) of the host cluster (defaults to the current context)")
+	hostNamespace := fs.String("host-namespace", "default", "namespace the FederatedCluster and Secret were created in")
+	kubeconfigPath := fs.String("kubeconfig", clientcmd.RecommendedHomeFile, "path to the kubeconfig holding the host cluster context")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *clusterName == "" {
+		return errors.New("--cluster-name is required")
+	}
+
+	hostClient, err := hostClientFor(*kubeconfigPath, *hostContext)
+	if err != nil {
+		return errors.Wrap(err, "failed to build host cluster client")
+	}
+
+	ctx := context.Background()
+	fedCluster := &federationv1.FederatedCluster{}
+	key := client.ObjectKey{Namespace: *hostNamespace, Name: *clusterName}
+	if err := hostClient.Get(ctx, key, fedCluster); err != nil {
+		if apierrors.IsNotFound(err) {
+			fmt.Fprintf(os.Stdout, "cluster %q already unjoined\n", *clusterName)
+			return nil
+		}
+		return errors.Wrapf(err, "failed to get federatedcluster %s/%s", *hostNamespace, *clusterName)
+	}
+
+	secretName := fedCluster.Spec.SecretRef.Name
+	if err := hostClient.Delete(ctx, fedCluster); err != nil && !apierrors.IsNotFound(err) {
+		return errors.Wrapf(err, "failed to delete federatedcluster %s/%s", *hostNamespace, *clusterName)
+	}
+
+	if secretName != "" {
+		secret := &apiv1.Secret{ObjectMeta: metav1.ObjectMeta{Name: secretName, Namespace: *hostNamespace}}
+		if err := hostClient.Delete(ctx, secret); err != nil && !apierrors.IsNotFound(err) {
+			return errors.Wrapf(err, "failed to delete secret %s/%s", *hostNamespace, secretName)
+		}
+	}
+
+	fmt.Fprintf(os.Stdout, "cluster %q unjoined from namespace %q\n", *clusterName, *hostNamespace)
+	return nil
+}
@@ -2,6 +2,7 @@ package controllers
 
 import (
 	"context"
+	"fmt"
 	"strings"
 	"time"
 
@@ -9,9 +10,15 @@ import (
 	"github.com/cloudfunny/kubefederation/api/v1/common"
 	"github.com/pkg/errors"
 	apiv1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/dynamic"
 	kubeclientset "k8s.io/client-go/kubernetes"
 	restclient "k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
@@ -25,8 +32,22 @@ const (
 	KubeAPIBurst      = 30
 	TokenKey          = "token"
 	CaCrtKey          = "ca.crt"
+	KubeconfigKey     = "kubeconfig"
 	KubeFedConfigName = "kubefed"
 
+	// ServiceAccountBootstrap defaults for the dedicated ServiceAccount and
+	// ClusterRoleBinding created in a member cluster during
+	// AuthMode: ServiceAccountBootstrap.
+	BootstrapServiceAccountName = "kubefederation-member"
+	BootstrapServiceAccountNS   = "kube-system"
+	BootstrapClusterRoleBinding = "kubefederation-member"
+	BootstrapTokenSecretName    = "kubefederation-member-token"
+
+	// BootstrapTokenPollInterval is how often FederatedClusterReconciler
+	// requeues a cluster while waiting for its bootstrap token Secret to
+	// populate.
+	BootstrapTokenPollInterval = 2 * time.Second
+
 	// Common ClusterConditions for KubeFedClusterStatus
 	ClusterReady                 = "ClusterReady"
 	HealthzOk                    = "/healthz responded with ok"
@@ -38,11 +59,16 @@ const (
 	ClusterReachableMsg          = "cluster is reachable"
 	ClusterConfigMalformedReason = "ClusterConfigMalformed"
 	ClusterConfigMalformedMsg    = "cluster's configuration may be malformed"
+
+	CompleteAPIEnablementsReason   = "CompleteAPIEnablements"
+	CompleteAPIEnablementsMsg      = "all discovered API group versions are enabled"
+	IncompleteAPIEnablementsReason = "IncompleteAPIEnablements"
 )
 
 type ClusterClient struct {
-	kubeClient  *kubeclientset.Clientset
-	clusterName string
+	kubeClient    *kubeclientset.Clientset
+	dynamicClient dynamic.Interface
+	clusterName   string
 }
 
 func NewClusterClientSet(c *federationv1.FederatedCluster, client client.Client, timeout time.Duration) (*ClusterClient, error) {
@@ -52,10 +78,58 @@ func NewClusterClientSet(c *federationv1.FederatedCluster, client client.Client,
 		return &clusterClientSet, err
 	}
 	clusterConfig.Timeout = timeout
-	clusterClientSet.kubeClient, err = kubeclientset.NewForConfig(restclient.AddUserAgent(clusterConfig, UserAgentName))
+	userAgentConfig := restclient.AddUserAgent(clusterConfig, UserAgentName)
+	clusterClientSet.kubeClient, err = kubeclientset.NewForConfig(userAgentConfig)
+	if err != nil {
+		return &clusterClientSet, err
+	}
+	clusterClientSet.dynamicClient, err = dynamic.NewForConfig(userAgentConfig)
 	return &clusterClientSet, err
 }
 
+// ApplyObject creates obj in the member cluster under gvr/namespace, or
+// updates it in place (preserving resourceVersion) if it already exists.
+func (c *ClusterClient) ApplyObject(ctx context.Context, gvr schema.GroupVersionResource, namespace string, obj *unstructured.Unstructured) error {
+	resourceClient := c.resourceInterface(gvr, namespace)
+
+	existing, err := resourceClient.Get(ctx, obj.GetName(), metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		_, err = resourceClient.Create(ctx, obj, metav1.CreateOptions{})
+		return err
+	}
+	if err != nil {
+		return err
+	}
+
+	obj.SetResourceVersion(existing.GetResourceVersion())
+	_, err = resourceClient.Update(ctx, obj, metav1.UpdateOptions{})
+	return err
+}
+
+// DeleteObject deletes name from the member cluster under gvr/namespace,
+// treating a not-found response as success.
+func (c *ClusterClient) DeleteObject(ctx context.Context, gvr schema.GroupVersionResource, namespace, name string) error {
+	err := c.resourceInterface(gvr, namespace).Delete(ctx, name, metav1.DeleteOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+// Clientset returns the member cluster's *kubernetes.Clientset, for callers
+// (such as ClusterClientManager) that need to build a long-lived shared
+// informer factory instead of issuing one-shot REST calls.
+func (c *ClusterClient) Clientset() *kubeclientset.Clientset {
+	return c.kubeClient
+}
+
+func (c *ClusterClient) resourceInterface(gvr schema.GroupVersionResource, namespace string) dynamic.ResourceInterface {
+	if namespace == "" {
+		return c.dynamicClient.Resource(gvr)
+	}
+	return c.dynamicClient.Resource(gvr).Namespace(namespace)
+}
+
 func buildClusterConfig(fedCluster *federationv1.FederatedCluster, client client.Client) (*restclient.Config, error) {
 	clusterName := fedCluster.Name
 
@@ -79,6 +153,17 @@ func buildClusterConfig(fedCluster *federationv1.FederatedCluster, client client
 		return nil, err
 	}
 
+	switch fedCluster.Spec.AuthMode {
+	case federationv1.ClusterAuthModeKubeconfig, federationv1.ClusterAuthModeServiceAccountBootstrap:
+		return buildClusterConfigFromKubeconfig(clusterName, secret)
+	default:
+		return buildClusterConfigFromToken(clusterName, fedCluster.Spec.APIEndpoint, fedCluster.Spec.CABundle, secret)
+	}
+}
+
+// buildClusterConfigFromToken is the original AuthMode: Token behavior,
+// pairing a bearer token secret with the CABundle on the spec.
+func buildClusterConfigFromToken(clusterName, apiEndpoint string, caBundle []byte, secret *apiv1.Secret) (*restclient.Config, error) {
 	token, tokenFound := secret.Data[TokenKey]
 	if !tokenFound || len(token) == 0 {
 		return nil, errors.Errorf("The secret for cluster %s is missing a non-empty value for %q", clusterName, TokenKey)
@@ -88,7 +173,7 @@ func buildClusterConfig(fedCluster *federationv1.FederatedCluster, client client
 	if err != nil {
 		return nil, err
 	}
-	clusterConfig.CAData = fedCluster.Spec.CABundle
+	clusterConfig.CAData = caBundle
 	clusterConfig.BearerToken = string(token)
 	clusterConfig.QPS = KubeAPIQPS
 	clusterConfig.Burst = KubeAPIBurst
@@ -96,6 +181,25 @@ func buildClusterConfig(fedCluster *federationv1.FederatedCluster, client client
 	return clusterConfig, nil
 }
 
+// buildClusterConfigFromKubeconfig covers AuthMode: Kubeconfig, and the
+// cluster-admin bootstrap credential used once by AuthMode:
+// ServiceAccountBootstrap before it switches to Token.
+func buildClusterConfigFromKubeconfig(clusterName string, secret *apiv1.Secret) (*restclient.Config, error) {
+	kubeconfig, found := secret.Data[KubeconfigKey]
+	if !found || len(kubeconfig) == 0 {
+		return nil, errors.Errorf("The secret for cluster %s is missing a non-empty value for %q", clusterName, KubeconfigKey)
+	}
+
+	clusterConfig, err := clientcmd.RESTConfigFromKubeConfig(kubeconfig)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse kubeconfig for cluster %s", clusterName)
+	}
+	clusterConfig.QPS = KubeAPIQPS
+	clusterConfig.Burst = KubeAPIBurst
+
+	return clusterConfig, nil
+}
+
 // GetClusterHealthStatus gets the kubernetes cluster health status by requesting "/healthz"
 func (c *ClusterClient) GetClusterHealthStatus() (*federationv1.FederatedClusterStatus, error) {
 	clusterStatus := federationv1.FederatedClusterStatus{}
@@ -168,3 +272,224 @@ func (c *ClusterClient) GetClusterHealthStatus() (*federationv1.FederatedCluster
 
 	return &clusterStatus, err
 }
+
+// CollectAPIEnablements discovers the API group versions and resources
+// served by the member cluster and returns them alongside a
+// CompleteAPIEnablements condition that is False when discovery could not
+// enumerate resources for one or more group versions, e.g. because an
+// aggregated APIService is down.
+func (c *ClusterClient) CollectAPIEnablements() ([]federationv1.APIEnablement, federationv1.ClusterCondition, error) {
+	currentTime := metav1.Now()
+
+	groups, err := c.kubeClient.DiscoveryClient.ServerGroups()
+	if err != nil {
+		reason := IncompleteAPIEnablementsReason
+		msg := errors.Wrapf(err, "failed to list API groups for cluster %q", c.clusterName).Error()
+		return nil, federationv1.ClusterCondition{
+			Type:               common.CompleteAPIEnablements,
+			Status:             apiv1.ConditionFalse,
+			Reason:             &reason,
+			Message:            &msg,
+			LastProbeTime:      currentTime,
+			LastTransitionTime: &currentTime,
+		}, err
+	}
+
+	var enablements []federationv1.APIEnablement
+	var failedGroupVersions []string
+	for _, group := range groups.Groups {
+		for _, gv := range group.Versions {
+			resourceList, err := c.kubeClient.DiscoveryClient.ServerResourcesForGroupVersion(gv.GroupVersion)
+			if err != nil {
+				runtime.HandleError(errors.Wrapf(err, "failed to list resources for group version %q on cluster %q", gv.GroupVersion, c.clusterName))
+				failedGroupVersions = append(failedGroupVersions, gv.GroupVersion)
+				continue
+			}
+			resources := make([]federationv1.APIResource, 0, len(resourceList.APIResources))
+			for _, res := range resourceList.APIResources {
+				resources = append(resources, federationv1.APIResource{
+					Kind:       res.Kind,
+					Name:       res.Name,
+					Namespaced: res.Namespaced,
+					Verbs:      res.Verbs,
+				})
+			}
+			enablements = append(enablements, federationv1.APIEnablement{
+				GroupVersion: gv.GroupVersion,
+				Resources:    resources,
+			})
+		}
+	}
+
+	if len(failedGroupVersions) > 0 {
+		reason := IncompleteAPIEnablementsReason
+		msg := fmt.Sprintf("discovery failed for group versions: %s", strings.Join(failedGroupVersions, ", "))
+		return enablements, federationv1.ClusterCondition{
+			Type:               common.CompleteAPIEnablements,
+			Status:             apiv1.ConditionFalse,
+			Reason:             &reason,
+			Message:            &msg,
+			LastProbeTime:      currentTime,
+			LastTransitionTime: &currentTime,
+		}, nil
+	}
+
+	reason := CompleteAPIEnablementsReason
+	msg := CompleteAPIEnablementsMsg
+	return enablements, federationv1.ClusterCondition{
+		Type:               common.CompleteAPIEnablements,
+		Status:             apiv1.ConditionTrue,
+		Reason:             &reason,
+		Message:            &msg,
+		LastProbeTime:      currentTime,
+		LastTransitionTime: &currentTime,
+	}, nil
+}
+
+// ClusterResourceSummary is the node/namespace inventory of a member
+// cluster, as collected by GetClusterResourceSummary.
+type ClusterResourceSummary struct {
+	NodeSummary       federationv1.NodeSummary
+	Allocatable       apiv1.ResourceList
+	Capacity          apiv1.ResourceList
+	KubernetesVersion string
+	Region            string
+	Zone              string
+}
+
+// GetClusterResourceSummary lists the member cluster's Nodes and Namespaces
+// and sums each node's status.allocatable/status.capacity (including
+// extended resources such as GPUs), so placement decisions have real
+// capacity data to work with.
+func (c *ClusterClient) GetClusterResourceSummary() (*ClusterResourceSummary, error) {
+	summary := &ClusterResourceSummary{
+		Allocatable: apiv1.ResourceList{},
+		Capacity:    apiv1.ResourceList{},
+	}
+
+	nodes, err := c.kubeClient.CoreV1().Nodes().List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list nodes for cluster %q", c.clusterName)
+	}
+
+	for _, node := range nodes.Items {
+		if isNodeReady(&node) {
+			summary.NodeSummary.Ready++
+		} else {
+			summary.NodeSummary.NotReady++
+		}
+
+		for name, quantity := range node.Status.Allocatable {
+			addToResourceList(summary.Allocatable, name, quantity)
+		}
+		for name, quantity := range node.Status.Capacity {
+			addToResourceList(summary.Capacity, name, quantity)
+		}
+
+		if summary.KubernetesVersion == "" {
+			summary.KubernetesVersion = node.Status.NodeInfo.KubeletVersion
+		}
+		if summary.Region == "" {
+			summary.Region = node.Labels[apiv1.LabelTopologyRegion]
+		}
+		if summary.Zone == "" {
+			summary.Zone = node.Labels[apiv1.LabelTopologyZone]
+		}
+	}
+
+	namespaces, err := c.kubeClient.CoreV1().Namespaces().List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list namespaces for cluster %q", c.clusterName)
+	}
+	summary.NodeSummary.NamespaceCount = int32(len(namespaces.Items))
+
+	return summary, nil
+}
+
+func isNodeReady(node *apiv1.Node) bool {
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == apiv1.NodeReady {
+			return condition.Status == apiv1.ConditionTrue
+		}
+	}
+	return false
+}
+
+func addToResourceList(list apiv1.ResourceList, name apiv1.ResourceName, quantity resource.Quantity) {
+	if existing, ok := list[name]; ok {
+		existing.Add(quantity)
+		list[name] = existing
+	} else {
+		list[name] = quantity.DeepCopy()
+	}
+}
+
+// EnsureBootstrapServiceAccount creates the dedicated ServiceAccount,
+// ClusterRoleBinding and token Secret used to bootstrap a member cluster's
+// day-to-day AuthMode: Token credentials, using the credentials c was built
+// from (expected to carry cluster-admin rights). It is idempotent: existing
+// resources are left as-is. Call BootstrapToken afterwards, on each
+// reconcile, to check whether the token Secret has populated yet.
+func (c *ClusterClient) EnsureBootstrapServiceAccount(ctx context.Context) error {
+	serviceAccount := &apiv1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      BootstrapServiceAccountName,
+			Namespace: BootstrapServiceAccountNS,
+		},
+	}
+	if _, err := c.kubeClient.CoreV1().ServiceAccounts(BootstrapServiceAccountNS).Create(ctx, serviceAccount, metav1.CreateOptions{}); err != nil && !apierrors.IsAlreadyExists(err) {
+		return errors.Wrapf(err, "failed to create bootstrap service account in cluster %q", c.clusterName)
+	}
+
+	clusterRoleBinding := &rbacv1.ClusterRoleBinding{
+		ObjectMeta: metav1.ObjectMeta{Name: BootstrapClusterRoleBinding},
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: rbacv1.GroupName,
+			Kind:     "ClusterRole",
+			Name:     "cluster-admin",
+		},
+		Subjects: []rbacv1.Subject{{
+			Kind:      rbacv1.ServiceAccountKind,
+			Name:      BootstrapServiceAccountName,
+			Namespace: BootstrapServiceAccountNS,
+		}},
+	}
+	if _, err := c.kubeClient.RbacV1().ClusterRoleBindings().Create(ctx, clusterRoleBinding, metav1.CreateOptions{}); err != nil && !apierrors.IsAlreadyExists(err) {
+		return errors.Wrapf(err, "failed to create bootstrap cluster role binding in cluster %q", c.clusterName)
+	}
+
+	tokenSecret := &apiv1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      BootstrapTokenSecretName,
+			Namespace: BootstrapServiceAccountNS,
+			Annotations: map[string]string{
+				apiv1.ServiceAccountNameKey: BootstrapServiceAccountName,
+			},
+		},
+		Type: apiv1.SecretTypeServiceAccountToken,
+	}
+	if _, err := c.kubeClient.CoreV1().Secrets(BootstrapServiceAccountNS).Create(ctx, tokenSecret, metav1.CreateOptions{}); err != nil && !apierrors.IsAlreadyExists(err) {
+		return errors.Wrapf(err, "failed to create bootstrap token secret in cluster %q", c.clusterName)
+	}
+	return nil
+}
+
+// BootstrapToken does a single, non-blocking check of whether the bootstrap
+// token Secret created by EnsureBootstrapServiceAccount has populated yet.
+// ready is false (with a nil error) while the control plane is still filling
+// it in; the caller is expected to retry on a later reconcile rather than
+// block waiting for it.
+func (c *ClusterClient) BootstrapToken(ctx context.Context) (token, caCrt []byte, ready bool, err error) {
+	populated, getErr := c.kubeClient.CoreV1().Secrets(BootstrapServiceAccountNS).Get(ctx, BootstrapTokenSecretName, metav1.GetOptions{})
+	if apierrors.IsNotFound(getErr) {
+		return nil, nil, false, nil
+	}
+	if getErr != nil {
+		return nil, nil, false, errors.Wrapf(getErr, "failed to get bootstrap token secret in cluster %q", c.clusterName)
+	}
+	tok, ok := populated.Data[TokenKey]
+	if !ok || len(tok) == 0 {
+		return nil, nil, false, nil
+	}
+	return tok, populated.Data[CaCrtKey], true, nil
+}
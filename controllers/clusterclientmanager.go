@@ -0,0 +1,257 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"math/rand"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/informers"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	federationv1 "github.com/cloudfunny/kubefederation/api/v1"
+)
+
+const (
+	// minProbeInterval is the background health probe's base interval,
+	// and also where its backoff resets to after a successful probe.
+	minProbeInterval = 30 * time.Second
+	// maxProbeBackoff caps the exponential backoff applied after
+	// consecutive failed probes.
+	maxProbeBackoff = 5 * time.Minute
+)
+
+// managedCluster is a long-lived client and shared informer factory for a
+// single FederatedCluster, kept warm across reconciles instead of being
+// rebuilt (and re-probed synchronously) on every one.
+type managedCluster struct {
+	clusterClient         *ClusterClient
+	informerFactory       informers.SharedInformerFactory
+	secretResourceVersion string
+	cancel                context.CancelFunc
+
+	mu     sync.RWMutex
+	status federationv1.FederatedClusterStatus
+}
+
+// LatestStatus returns the most recent health status the background probe
+// observed for this cluster.
+func (m *managedCluster) LatestStatus() federationv1.FederatedClusterStatus {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return *m.status.DeepCopy()
+}
+
+func (m *managedCluster) setStatus(status federationv1.FederatedClusterStatus) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.status = status
+}
+
+// ClusterClient returns the cluster's long-lived client, e.g. for use by the
+// propagation subsystem instead of opening a new connection per object.
+func (m *managedCluster) ClusterClient() *ClusterClient {
+	return m.clusterClient
+}
+
+// InformerFactory returns the cluster's shared informer factory.
+func (m *managedCluster) InformerFactory() informers.SharedInformerFactory {
+	return m.informerFactory
+}
+
+// ClusterClientManager keeps a long-lived client and shared informer
+// factory per FederatedCluster, probing health in the background on a
+// jittered interval with exponential backoff on failure, instead of
+// FederatedClusterReconciler.Reconcile doing a blocking /healthz call (with
+// no backoff) on every enqueue. It is also what actually persists
+// status.conditions; Reconcile just reads the latest cached result.
+type ClusterClientManager struct {
+	client.Client
+	Log logr.Logger
+
+	mu       sync.Mutex
+	clusters map[types.NamespacedName]*managedCluster
+}
+
+// NewClusterClientManager builds a ClusterClientManager backed by c.
+func NewClusterClientManager(c client.Client, log logr.Logger) *ClusterClientManager {
+	return &ClusterClientManager{
+		Client:   c,
+		Log:      log,
+		clusters: map[types.NamespacedName]*managedCluster{},
+	}
+}
+
+var (
+	sharedClusterClientManagerOnce sync.Once
+	sharedClusterClientManager     *ClusterClientManager
+)
+
+// defaultClusterClientManager returns a single process-wide
+// ClusterClientManager, built from c/log the first time either
+// FederatedClusterReconciler or FederatedObjectReconciler needs one.
+// Sharing it keeps both controllers reading/writing the same cached
+// clients, informers and probeLoop goroutines per cluster, instead of each
+// reconciler running (and probing) its own independent copy.
+func defaultClusterClientManager(c client.Client, log logr.Logger) *ClusterClientManager {
+	sharedClusterClientManagerOnce.Do(func() {
+		sharedClusterClientManager = NewClusterClientManager(c, log)
+	})
+	return sharedClusterClientManager
+}
+
+// EnsureCluster returns the cached managedCluster for fedCluster, building
+// (or, if the referenced Secret's resourceVersion has changed, rebuilding)
+// it as needed, and starting its background probe loop.
+func (m *ClusterClientManager) EnsureCluster(ctx context.Context, fedCluster *federationv1.FederatedCluster) (*managedCluster, error) {
+	key := types.NamespacedName{Namespace: fedCluster.Namespace, Name: fedCluster.Name}
+
+	secret := &apiv1.Secret{}
+	secretKey := types.NamespacedName{Namespace: fedCluster.Namespace, Name: fedCluster.Spec.SecretRef.Name}
+	if err := m.Get(ctx, secretKey, secret); err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if existing, ok := m.clusters[key]; ok {
+		if existing.secretResourceVersion == secret.ResourceVersion {
+			return existing, nil
+		}
+		existing.cancel()
+		delete(m.clusters, key)
+	}
+
+	clusterClient, err := NewClusterClientSet(fedCluster, m.Client, time.Second*5)
+	if err != nil {
+		return nil, err
+	}
+
+	probeCtx, cancel := context.WithCancel(context.Background())
+	managed := &managedCluster{
+		clusterClient:         clusterClient,
+		informerFactory:       informers.NewSharedInformerFactory(clusterClient.Clientset(), 30*time.Second),
+		secretResourceVersion: secret.ResourceVersion,
+		cancel:                cancel,
+	}
+	managed.informerFactory.Start(probeCtx.Done())
+	m.clusters[key] = managed
+
+	go m.probeLoop(probeCtx, key, managed)
+
+	return managed, nil
+}
+
+// RemoveCluster stops and evicts the cached entry for key, if any.
+func (m *ClusterClientManager) RemoveCluster(key types.NamespacedName) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if existing, ok := m.clusters[key]; ok {
+		existing.cancel()
+		delete(m.clusters, key)
+	}
+}
+
+// probeLoop periodically calls GetClusterHealthStatus for managed, caching
+// the result and persisting its conditions onto the live FederatedCluster.
+// The interval resets to minProbeInterval on success and doubles (capped at
+// maxProbeBackoff) on failure, with up to 25% jitter added each time so a
+// fleet of flapping clusters doesn't probe in lockstep.
+func (m *ClusterClientManager) probeLoop(ctx context.Context, key types.NamespacedName, managed *managedCluster) {
+	interval := minProbeInterval
+	for {
+		status, err := managed.clusterClient.GetClusterHealthStatus()
+		if err != nil {
+			interval *= 2
+			if interval > maxProbeBackoff {
+				interval = maxProbeBackoff
+			}
+		} else {
+			interval = minProbeInterval
+		}
+
+		if status != nil {
+			managed.setStatus(*status)
+			m.persistConditions(ctx, key, status.Conditions)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(interval + jitter(interval)):
+		}
+	}
+}
+
+// jitter returns a random duration in [0, interval/4).
+func jitter(interval time.Duration) time.Duration {
+	quarter := int64(interval / 4)
+	if quarter <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(quarter))
+}
+
+// persistConditions merges newConditions into the live FederatedCluster's
+// status.conditions (replacing any existing condition of the same Type),
+// and updates the object if anything changed.
+func (m *ClusterClientManager) persistConditions(ctx context.Context, key types.NamespacedName, newConditions []federationv1.ClusterCondition) {
+	current := &federationv1.FederatedCluster{}
+	if err := m.Get(ctx, key, current); err != nil {
+		return
+	}
+
+	merged := append([]federationv1.ClusterCondition(nil), current.Status.Conditions...)
+	for _, condition := range newConditions {
+		merged = mergeCondition(merged, condition)
+	}
+	if reflect.DeepEqual(merged, current.Status.Conditions) {
+		return
+	}
+
+	current.Status.Conditions = merged
+	if err := m.Status().Update(ctx, current); err != nil {
+		m.Log.Info("Failed to persist cluster health conditions", "cluster", key, "error", err.Error())
+	}
+}
+
+// mergeCondition returns conditions with newCondition replacing any
+// existing entry of the same Type, or appended if there was none.
+func mergeCondition(conditions []federationv1.ClusterCondition, newCondition federationv1.ClusterCondition) []federationv1.ClusterCondition {
+	for i, condition := range conditions {
+		if condition.Type == newCondition.Type {
+			conditions[i] = newCondition
+			return conditions
+		}
+	}
+	return append(conditions, newCondition)
+}
+
+// mergeConditions applies mergeCondition for each of newConditions in turn.
+func mergeConditions(conditions []federationv1.ClusterCondition, newConditions []federationv1.ClusterCondition) []federationv1.ClusterCondition {
+	for _, condition := range newConditions {
+		conditions = mergeCondition(conditions, condition)
+	}
+	return conditions
+}
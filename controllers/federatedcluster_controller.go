@@ -19,22 +19,59 @@ package controllers
 import (
 	"context"
 	"reflect"
+	"sync"
 	"time"
 
+	apiv1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	federationv1 "github.com/cloudfunny/kubefederation/api/v1"
+	"github.com/cloudfunny/kubefederation/api/v1/common"
 	"github.com/go-logr/logr"
 )
 
+// defaultResourceSummaryInterval is used when
+// FederatedClusterReconciler.ResourceSummaryInterval is left unset.
+const defaultResourceSummaryInterval = time.Minute
+
+// defaultNotReadyTolerationWindow is used when
+// FederatedClusterReconciler.NotReadyTolerationWindow is left unset.
+const defaultNotReadyTolerationWindow = time.Minute
+
 // FederatedClusterReconciler reconciles a FederatedCluster object
 type FederatedClusterReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
 	Log    logr.Logger
+
+	// ResourceSummaryInterval is the minimum time between node/resource
+	// summary collections for a given cluster. Defaults to
+	// defaultResourceSummaryInterval.
+	ResourceSummaryInterval time.Duration
+
+	resourceSummaryMu       sync.Mutex
+	lastResourceSummaryTime map[types.NamespacedName]time.Time
+
+	// NotReadyTolerationWindow is how long the ClusterReady condition must
+	// stay False before the cluster is auto-tainted with TaintKeyNotReady.
+	// Defaults to defaultNotReadyTolerationWindow.
+	NotReadyTolerationWindow time.Duration
+
+	notReadySinceMu sync.Mutex
+	notReadySince   map[types.NamespacedName]time.Time
+
+	// ClusterManager keeps the long-lived per-cluster client/informers and
+	// runs the background health probe; Reconcile reads its cached status
+	// instead of probing /healthz itself. Lazily created if left nil.
+	ClusterManager *ClusterClientManager
+	managerOnce    sync.Once
 }
 
 //+kubebuilder:rbac:groups=federation.example.com,resources=federatedclusters,verbs=get;list;watch;create;update;patch;delete
@@ -54,19 +91,65 @@ func (r *FederatedClusterReconciler) Reconcile(ctx context.Context, req ctrl.Req
 	log := r.Log.WithValues("FederatedCluster", req.NamespacedName)
 	federatedCluster := &federationv1.FederatedCluster{}
 	if err := r.Get(ctx, req.NamespacedName, federatedCluster); err != nil {
+		if apierrors.IsNotFound(err) {
+			r.clusterManager().RemoveCluster(req.NamespacedName)
+		}
 		log.Info("Failed to get FederatedCluster", "cluster", req.NamespacedName)
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
 
-	clusterClient, err := NewClusterClientSet(federatedCluster, r.Client, time.Second*5)
+	if federatedCluster.Spec.AuthMode == federationv1.ClusterAuthModeServiceAccountBootstrap {
+		return r.reconcileServiceAccountBootstrap(ctx, req.NamespacedName, federatedCluster)
+	}
+
+	managed, err := r.clusterManager().EnsureCluster(ctx, federatedCluster)
 	if err != nil {
-		log.Info("Failed to generate new clusterset", "cluster", req.NamespacedName)
+		log.Info("Failed to ensure cached cluster client", "cluster", req.NamespacedName, "error", err.Error())
 		return ctrl.Result{}, err
 	}
+	clusterClient := managed.ClusterClient()
 
-	clusterStatus, err := clusterClient.GetClusterHealthStatus()
+	// clusterStatus is seeded from the object's last-persisted status:
+	// conditions.Ready/Offline are kept current by the manager's
+	// background probe (ClusterClientManager.persistConditions), not
+	// recomputed here.
+	clusterStatus := federatedCluster.Status.DeepCopy()
+	clusterStatus.Conditions = mergeConditions(clusterStatus.Conditions, managed.LatestStatus().Conditions)
+
+	apiEnablements, apiEnablementsCondition, err := clusterClient.CollectAPIEnablements()
 	if err != nil {
-		log.Info("Failed to get cluster status", "cluster", req.NamespacedName)
+		log.Info("Failed to collect API enablements", "cluster", req.NamespacedName)
+		clusterStatus.APIEnablements = federatedCluster.Status.APIEnablements
+	} else {
+		clusterStatus.APIEnablements = apiEnablements
+	}
+	clusterStatus.Conditions = mergeCondition(clusterStatus.Conditions, apiEnablementsCondition)
+
+	if r.shouldCollectResourceSummary(req.NamespacedName) {
+		summary, err := clusterClient.GetClusterResourceSummary()
+		if err != nil {
+			log.Info("Failed to collect cluster resource summary", "cluster", req.NamespacedName)
+			summary = nil
+		}
+		if summary != nil {
+			clusterStatus.NodeSummary = &summary.NodeSummary
+			clusterStatus.Allocatable = summary.Allocatable
+			clusterStatus.Capacity = summary.Capacity
+			clusterStatus.KubernetesVersion = summary.KubernetesVersion
+			clusterStatus.Region = summary.Region
+			clusterStatus.Zone = summary.Zone
+		}
+	} else {
+		clusterStatus.NodeSummary = federatedCluster.Status.NodeSummary
+		clusterStatus.Allocatable = federatedCluster.Status.Allocatable
+		clusterStatus.Capacity = federatedCluster.Status.Capacity
+		clusterStatus.KubernetesVersion = federatedCluster.Status.KubernetesVersion
+		clusterStatus.Region = federatedCluster.Status.Region
+		clusterStatus.Zone = federatedCluster.Status.Zone
+	}
+
+	if err := r.reconcileTaints(ctx, req.NamespacedName, federatedCluster, clusterStatus); err != nil {
+		log.Info("Failed to reconcile taints", "cluster", req.NamespacedName, "error", err.Error())
 	}
 
 	if !reflect.DeepEqual(clusterStatus, federatedCluster.Status) {
@@ -82,9 +165,219 @@ func (r *FederatedClusterReconciler) Reconcile(ctx context.Context, req ctrl.Req
 	return ctrl.Result{}, nil
 }
 
+// reconcileServiceAccountBootstrap drives the one-time AuthMode:
+// ServiceAccountBootstrap flow: it uses a client built from the
+// cluster-admin kubeconfig in SecretRef to create a dedicated ServiceAccount
+// and ClusterRoleBinding in the member cluster, then polls its token Secret
+// without blocking the worker — requeueing at BootstrapTokenPollInterval
+// while it's still populating — before rewriting SecretRef to the resulting
+// token and switching AuthMode to Token.
+func (r *FederatedClusterReconciler) reconcileServiceAccountBootstrap(ctx context.Context, clusterKey types.NamespacedName, federatedCluster *federationv1.FederatedCluster) (ctrl.Result, error) {
+	clusterClient, err := NewClusterClientSet(federatedCluster, r.Client, time.Second*5)
+	if err != nil {
+		r.Log.Info("Failed to generate new clusterset", "cluster", clusterKey)
+		return ctrl.Result{}, err
+	}
+
+	if err := clusterClient.EnsureBootstrapServiceAccount(ctx); err != nil {
+		r.Log.Info("Failed to ensure bootstrap service account", "cluster", clusterKey, "error", err.Error())
+		return ctrl.Result{}, err
+	}
+
+	token, caCrt, ready, err := clusterClient.BootstrapToken(ctx)
+	if err != nil {
+		r.Log.Info("Failed to poll bootstrap token", "cluster", clusterKey, "error", err.Error())
+		return ctrl.Result{}, err
+	}
+	if !ready {
+		return ctrl.Result{RequeueAfter: BootstrapTokenPollInterval}, nil
+	}
+
+	secret := &apiv1.Secret{}
+	secretKey := client.ObjectKey{Namespace: federatedCluster.Namespace, Name: federatedCluster.Spec.SecretRef.Name}
+	if err := r.Get(ctx, secretKey, secret); err != nil {
+		return ctrl.Result{}, err
+	}
+	secret.Data = map[string][]byte{
+		TokenKey: token,
+		CaCrtKey: caCrt,
+	}
+	if err := r.Update(ctx, secret); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	federatedCluster.Spec.AuthMode = federationv1.ClusterAuthModeToken
+	if err := r.Update(ctx, federatedCluster); err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{Requeue: true}, nil
+}
+
+// shouldCollectResourceSummary reports whether enough time has passed since
+// the last node/resource summary collection for clusterKey, and records the
+// attempt time if so.
+func (r *FederatedClusterReconciler) shouldCollectResourceSummary(clusterKey types.NamespacedName) bool {
+	interval := r.ResourceSummaryInterval
+	if interval <= 0 {
+		interval = defaultResourceSummaryInterval
+	}
+
+	r.resourceSummaryMu.Lock()
+	defer r.resourceSummaryMu.Unlock()
+	if r.lastResourceSummaryTime == nil {
+		r.lastResourceSummaryTime = map[types.NamespacedName]time.Time{}
+	}
+
+	last, ok := r.lastResourceSummaryTime[clusterKey]
+	now := time.Now()
+	if ok && now.Sub(last) < interval {
+		return false
+	}
+	r.lastResourceSummaryTime[clusterKey] = now
+	return true
+}
+
+// reconcileTaints auto-taints federatedCluster based on the conditions in
+// clusterStatus: TaintKeyUnreachable/NoExecute as soon as ClusterOffline is
+// True, and TaintKeyNotReady/NoSchedule once ClusterReady has been False for
+// longer than NotReadyTolerationWindow. clusterStatus.Evictions is updated
+// to match; if the taint set changed, federatedCluster.Spec is persisted.
+func (r *FederatedClusterReconciler) reconcileTaints(ctx context.Context, clusterKey types.NamespacedName, federatedCluster *federationv1.FederatedCluster, clusterStatus *federationv1.FederatedClusterStatus) error {
+	readyFalse := conditionStatus(clusterStatus.Conditions, common.ClusterReady) == apiv1.ConditionFalse
+	offlineTrue := conditionStatus(clusterStatus.Conditions, common.ClusterOffline) == apiv1.ConditionTrue
+
+	window := r.NotReadyTolerationWindow
+	if window <= 0 {
+		window = defaultNotReadyTolerationWindow
+	}
+	notReadySince := r.trackNotReadySince(clusterKey, readyFalse)
+	wantNotReadyTaint := notReadySince != nil && time.Since(*notReadySince) >= window
+
+	taints := federatedCluster.Spec.Taints
+	evictions := clusterStatus.Evictions
+	var changed bool
+	taints, evictions, changed = reconcileManagedTaint(taints, evictions, federationv1.TaintKeyNotReady, federationv1.TaintEffectNoSchedule, wantNotReadyTaint)
+	var changedOffline bool
+	taints, evictions, changedOffline = reconcileManagedTaint(taints, evictions, federationv1.TaintKeyUnreachable, federationv1.TaintEffectNoExecute, offlineTrue)
+
+	clusterStatus.Evictions = evictions
+	federatedCluster.Spec.Taints = taints
+	if changed || changedOffline {
+		return r.Update(ctx, federatedCluster)
+	}
+	return nil
+}
+
+// trackNotReadySince records the first time ClusterReady was observed
+// False for clusterKey and returns that time, or nil once it's True again.
+func (r *FederatedClusterReconciler) trackNotReadySince(clusterKey types.NamespacedName, readyFalse bool) *time.Time {
+	r.notReadySinceMu.Lock()
+	defer r.notReadySinceMu.Unlock()
+	if r.notReadySince == nil {
+		r.notReadySince = map[types.NamespacedName]time.Time{}
+	}
+
+	if !readyFalse {
+		delete(r.notReadySince, clusterKey)
+		return nil
+	}
+
+	since, ok := r.notReadySince[clusterKey]
+	if !ok {
+		since = time.Now()
+		r.notReadySince[clusterKey] = since
+	}
+	return &since
+}
+
+// conditionStatus returns the Status of the first condition of type t, or
+// ConditionUnknown if none is present.
+func conditionStatus(conditions []federationv1.ClusterCondition, t common.ClusterConditionType) apiv1.ConditionStatus {
+	for _, condition := range conditions {
+		if condition.Type == t {
+			return condition.Status
+		}
+	}
+	return apiv1.ConditionUnknown
+}
+
+// reconcileManagedTaint adds or removes the controller-managed taint/
+// eviction pair identified by key, returning the updated slices and whether
+// anything changed.
+func reconcileManagedTaint(taints []federationv1.Taint, evictions []federationv1.TaintEviction, key string, effect federationv1.TaintEffect, want bool) ([]federationv1.Taint, []federationv1.TaintEviction, bool) {
+	index := -1
+	for i, taint := range taints {
+		if taint.Key == key {
+			index = i
+			break
+		}
+	}
+
+	if want {
+		if index >= 0 {
+			return taints, evictions, false
+		}
+		now := metav1.Now()
+		taints = append(taints, federationv1.Taint{Key: key, Effect: effect, TimeAdded: &now})
+		evictions = append(evictions, federationv1.TaintEviction{TaintKey: key, Effect: effect, Timestamp: now})
+		return taints, evictions, true
+	}
+
+	if index < 0 {
+		return taints, evictions, false
+	}
+	taints = append(taints[:index], taints[index+1:]...)
+	for i, eviction := range evictions {
+		if eviction.TaintKey == key {
+			evictions = append(evictions[:i], evictions[i+1:]...)
+			break
+		}
+	}
+	return taints, evictions, true
+}
+
+// clusterManager returns r.ClusterManager, falling back to the
+// process-wide shared manager (see defaultClusterClientManager) the first
+// time it's needed, so this reconciler and FederatedObjectReconciler
+// resolve to the same cached clients/informers/probe loops instead of each
+// running their own.
+func (r *FederatedClusterReconciler) clusterManager() *ClusterClientManager {
+	r.managerOnce.Do(func() {
+		if r.ClusterManager == nil {
+			r.ClusterManager = defaultClusterClientManager(r.Client, r.Log)
+		}
+	})
+	return r.ClusterManager
+}
+
+// secretToFederatedClusters maps a Secret to reconcile requests for every
+// FederatedCluster in its namespace that references it via SecretRef, so a
+// rotated/updated Secret promptly evicts ClusterClientManager's cached
+// client for that cluster instead of waiting for its next scheduled probe.
+func (r *FederatedClusterReconciler) secretToFederatedClusters(secret client.Object) []ctrl.Request {
+	clusterList := &federationv1.FederatedClusterList{}
+	if err := r.List(context.Background(), clusterList, client.InNamespace(secret.GetNamespace())); err != nil {
+		return nil
+	}
+
+	var requests []ctrl.Request
+	for _, cluster := range clusterList.Items {
+		if cluster.Spec.SecretRef.Name == secret.GetName() {
+			requests = append(requests, ctrl.Request{
+				NamespacedName: types.NamespacedName{Namespace: cluster.Namespace, Name: cluster.Name},
+			})
+		}
+	}
+	return requests
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *FederatedClusterReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&federationv1.FederatedCluster{}).
+		Watches(
+			&source.Kind{Type: &apiv1.Secret{}},
+			handler.EnqueueRequestsFromMapFunc(r.secretToFederatedClusters),
+		).
 		Complete(r)
 }
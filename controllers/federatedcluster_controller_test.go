@@ -0,0 +1,81 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+
+	federationv1 "github.com/cloudfunny/kubefederation/api/v1"
+)
+
+func TestReconcileManagedTaint(t *testing.T) {
+	t.Run("adds the taint and eviction record when wanted and absent", func(t *testing.T) {
+		taints, evictions, changed := reconcileManagedTaint(nil, nil, federationv1.TaintKeyNotReady, federationv1.TaintEffectNoSchedule, true)
+		if !changed {
+			t.Fatal("expected changed = true")
+		}
+		if len(taints) != 1 || taints[0].Key != federationv1.TaintKeyNotReady {
+			t.Fatalf("taints = %+v, want one taint with key %q", taints, federationv1.TaintKeyNotReady)
+		}
+		if len(evictions) != 1 || evictions[0].TaintKey != federationv1.TaintKeyNotReady {
+			t.Fatalf("evictions = %+v, want one eviction with key %q", evictions, federationv1.TaintKeyNotReady)
+		}
+	})
+
+	t.Run("is a no-op when wanted and already present", func(t *testing.T) {
+		taints := []federationv1.Taint{{Key: federationv1.TaintKeyNotReady, Effect: federationv1.TaintEffectNoSchedule}}
+		evictions := []federationv1.TaintEviction{{TaintKey: federationv1.TaintKeyNotReady, Effect: federationv1.TaintEffectNoSchedule}}
+		newTaints, newEvictions, changed := reconcileManagedTaint(taints, evictions, federationv1.TaintKeyNotReady, federationv1.TaintEffectNoSchedule, true)
+		if changed {
+			t.Error("expected changed = false")
+		}
+		if len(newTaints) != 1 || len(newEvictions) != 1 {
+			t.Errorf("taints/evictions were modified unexpectedly: %+v / %+v", newTaints, newEvictions)
+		}
+	})
+
+	t.Run("removes the taint and eviction record when not wanted", func(t *testing.T) {
+		taints := []federationv1.Taint{
+			{Key: "other-key", Effect: federationv1.TaintEffectNoExecute},
+			{Key: federationv1.TaintKeyNotReady, Effect: federationv1.TaintEffectNoSchedule},
+		}
+		evictions := []federationv1.TaintEviction{
+			{TaintKey: "other-key", Effect: federationv1.TaintEffectNoExecute},
+			{TaintKey: federationv1.TaintKeyNotReady, Effect: federationv1.TaintEffectNoSchedule},
+		}
+		newTaints, newEvictions, changed := reconcileManagedTaint(taints, evictions, federationv1.TaintKeyNotReady, federationv1.TaintEffectNoSchedule, false)
+		if !changed {
+			t.Fatal("expected changed = true")
+		}
+		if len(newTaints) != 1 || newTaints[0].Key != "other-key" {
+			t.Fatalf("taints = %+v, want only other-key left", newTaints)
+		}
+		if len(newEvictions) != 1 || newEvictions[0].TaintKey != "other-key" {
+			t.Fatalf("evictions = %+v, want only other-key left", newEvictions)
+		}
+	})
+
+	t.Run("is a no-op when not wanted and already absent", func(t *testing.T) {
+		taints, evictions, changed := reconcileManagedTaint(nil, nil, federationv1.TaintKeyNotReady, federationv1.TaintEffectNoSchedule, false)
+		if changed {
+			t.Error("expected changed = false")
+		}
+		if len(taints) != 0 || len(evictions) != 0 {
+			t.Errorf("taints/evictions should remain empty, got %+v / %+v", taints, evictions)
+		}
+	})
+}
@@ -0,0 +1,635 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"sync"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	federationv1 "github.com/cloudfunny/kubefederation/api/v1"
+	"github.com/cloudfunny/kubefederation/api/v1/common"
+	"github.com/cloudfunny/kubefederation/pkg/taintutil"
+)
+
+// federatedObjectFinalizer lets Reconcile delete the propagated copies from
+// every member cluster they were applied to before the FederatedObject
+// itself is removed from the host cluster's API server.
+const federatedObjectFinalizer = "federation.example.com/federatedobject"
+
+// FederatedObjectReconciler reconciles a FederatedObject object
+type FederatedObjectReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+	Log    logr.Logger
+
+	// ClusterManager supplies the long-lived, cached per-cluster client this
+	// reconciler applies objects through, instead of opening a new
+	// connection to every target cluster on every reconcile. Lazily created
+	// if left nil.
+	ClusterManager *ClusterClientManager
+	managerOnce    sync.Once
+}
+
+// clusterManager returns r.ClusterManager, falling back to the
+// process-wide shared manager (see defaultClusterClientManager) the first
+// time it's needed, so this reconciler and FederatedClusterReconciler
+// resolve to the same cached clients/informers/probe loops instead of each
+// running their own.
+func (r *FederatedObjectReconciler) clusterManager() *ClusterClientManager {
+	r.managerOnce.Do(func() {
+		if r.ClusterManager == nil {
+			r.ClusterManager = defaultClusterClientManager(r.Client, r.Log)
+		}
+	})
+	return r.ClusterManager
+}
+
+//+kubebuilder:rbac:groups=federation.example.com,resources=federatedobjects,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=federation.example.com,resources=federatedobjects/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=federation.example.com,resources=federatedobjects/finalizers,verbs=update
+//+kubebuilder:rbac:groups=federation.example.com,resources=propagationpolicies,verbs=get;list;watch
+//+kubebuilder:rbac:groups=federation.example.com,resources=federatedclusters,verbs=get;list;watch
+
+// Reconcile resolves the Placement of a FederatedObject to a set of member
+// clusters, builds the per-cluster manifest by applying any overrides, and
+// creates/updates the result in each cluster via its ClusterClient.
+func (r *FederatedObjectReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := r.Log.WithValues("FederatedObject", req.NamespacedName)
+	fedObject := &federationv1.FederatedObject{}
+	if err := r.Get(ctx, req.NamespacedName, fedObject); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	template := &unstructured.Unstructured{}
+	if err := json.Unmarshal(fedObject.Spec.Template.Raw, template); err != nil {
+		log.Info("Failed to decode template", "federatedobject", req.NamespacedName, "error", err.Error())
+		return ctrl.Result{}, nil
+	}
+	gvk := template.GroupVersionKind()
+	namespace := template.GetNamespace()
+	if namespace == "" {
+		namespace = fedObject.Namespace
+	}
+
+	if !fedObject.GetDeletionTimestamp().IsZero() {
+		return r.reconcileDelete(ctx, fedObject, template, gvk, namespace)
+	}
+	if !controllerutil.ContainsFinalizer(fedObject, federatedObjectFinalizer) {
+		controllerutil.AddFinalizer(fedObject, federatedObjectFinalizer)
+		return ctrl.Result{}, r.Update(ctx, fedObject)
+	}
+
+	matchedClusters, policySpec, err := r.resolveTargetClusters(ctx, fedObject)
+	if err != nil {
+		log.Info("Failed to resolve target clusters", "federatedobject", req.NamespacedName, "error", err.Error())
+		return ctrl.Result{}, err
+	}
+
+	previouslyApplied := make(map[string]bool, len(fedObject.Status.Clusters))
+	for _, status := range fedObject.Status.Clusters {
+		if status.Status == common.ClusterPropagationOK {
+			previouslyApplied[status.Cluster] = true
+		}
+	}
+	targetClusters := filterByTaints(matchedClusters, fedObject.Spec.Placement.Tolerations, previouslyApplied)
+
+	if err := r.seedPendingStatuses(ctx, fedObject, targetClusters); err != nil {
+		log.Info("Failed to seed pending cluster statuses", "federatedobject", req.NamespacedName, "error", err.Error())
+	}
+
+	overrides := fedObject.Spec.Overrides
+	if policySpec != nil && policySpec.PlacementMode == common.PlacementModeDivide {
+		divided := divideReplicaOverrides(template, targetClusters, policySpec.MinReplicas, policySpec.MaxReplicas, overrides)
+		overrides = append(append([]federationv1.ClusterOverride{}, overrides...), divided...)
+	}
+
+	targetNames := make(map[string]bool, len(targetClusters))
+	statuses := make([]federationv1.PropagatedClusterStatus, 0, len(targetClusters))
+	for _, cluster := range targetClusters {
+		targetNames[cluster.Name] = true
+
+		manifest, err := applyOverrides(template, overrides, cluster.Name)
+		if err != nil {
+			statuses = append(statuses, failedStatus(cluster.Name, err))
+			continue
+		}
+		manifest.SetNamespace(namespace)
+
+		gvr, err := resolveGVR(gvk, &cluster)
+		if err != nil {
+			statuses = append(statuses, failedStatus(cluster.Name, err))
+			continue
+		}
+
+		managed, err := r.clusterManager().EnsureCluster(ctx, &cluster)
+		if err != nil {
+			statuses = append(statuses, failedStatus(cluster.Name, err))
+			continue
+		}
+		clusterClient := managed.ClusterClient()
+		if err := clusterClient.ApplyObject(ctx, gvr, namespace, manifest); err != nil {
+			statuses = append(statuses, failedStatus(cluster.Name, err))
+			continue
+		}
+		statuses = append(statuses, federationv1.PropagatedClusterStatus{
+			Cluster: cluster.Name,
+			Status:  common.ClusterPropagationOK,
+		})
+	}
+
+	// A cluster the object was previously applied to but that fell out of
+	// targetClusters this pass (Placement narrowed, the cluster stopped
+	// matching, or it picked up a taint) still has the real object running
+	// there unless we clean it up ourselves.
+	var dropped []string
+	for clusterName := range previouslyApplied {
+		if !targetNames[clusterName] {
+			dropped = append(dropped, clusterName)
+		}
+	}
+	if len(dropped) > 0 {
+		r.deleteFromClusters(ctx, fedObject, template, fedObject.Spec.Overrides, gvk, namespace, dropped)
+	}
+
+	statuses = mergeClusterStatusTimes(fedObject.Status.Clusters, statuses)
+	if !reflect.DeepEqual(statuses, fedObject.Status.Clusters) {
+		fedObject.Status.Clusters = statuses
+		if err := r.Status().Update(ctx, fedObject); err != nil {
+			if apierrors.IsConflict(err) {
+				return ctrl.Result{Requeue: true}, nil
+			}
+			log.Info("Failed to update federatedobject status", "federatedobject", req.NamespacedName, "error", err.Error())
+		}
+	}
+	return ctrl.Result{}, nil
+}
+
+// seedPendingStatuses marks any targetCluster with no existing entry in
+// fedObject.Status.Clusters as ClusterPropagationPending and persists that,
+// so status reflects reality while the apply loop below is still running
+// rather than jumping straight from absent to Applied/Failed.
+func (r *FederatedObjectReconciler) seedPendingStatuses(ctx context.Context, fedObject *federationv1.FederatedObject, targetClusters []federationv1.FederatedCluster) error {
+	known := make(map[string]bool, len(fedObject.Status.Clusters))
+	for _, status := range fedObject.Status.Clusters {
+		known[status.Cluster] = true
+	}
+
+	var pending []federationv1.PropagatedClusterStatus
+	for _, cluster := range targetClusters {
+		if !known[cluster.Name] {
+			pending = append(pending, federationv1.PropagatedClusterStatus{
+				Cluster:        cluster.Name,
+				Status:         common.ClusterPropagationPending,
+				LastUpdateTime: metav1.Now(),
+			})
+		}
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+
+	fedObject.Status.Clusters = append(fedObject.Status.Clusters, pending...)
+	return r.Status().Update(ctx, fedObject)
+}
+
+// reconcileDelete deletes fedObject's propagated copy from every cluster it
+// was last successfully applied to, then removes federatedObjectFinalizer
+// so deletion can proceed. Called once fedObject.DeletionTimestamp is set;
+// a no-op if the finalizer was already removed (e.g. a retry after this
+// already succeeded).
+func (r *FederatedObjectReconciler) reconcileDelete(ctx context.Context, fedObject *federationv1.FederatedObject, template *unstructured.Unstructured, gvk schema.GroupVersionKind, namespace string) (ctrl.Result, error) {
+	if !controllerutil.ContainsFinalizer(fedObject, federatedObjectFinalizer) {
+		return ctrl.Result{}, nil
+	}
+
+	var appliedClusters []string
+	for _, status := range fedObject.Status.Clusters {
+		if status.Status == common.ClusterPropagationOK {
+			appliedClusters = append(appliedClusters, status.Cluster)
+		}
+	}
+	r.deleteFromClusters(ctx, fedObject, template, fedObject.Spec.Overrides, gvk, namespace, appliedClusters)
+
+	controllerutil.RemoveFinalizer(fedObject, federatedObjectFinalizer)
+	return ctrl.Result{}, r.Update(ctx, fedObject)
+}
+
+// deleteFromClusters issues a best-effort DeleteObject for template/gvk
+// (with overrides re-applied, so the name matches whatever ApplyObject
+// actually created) in each of clusterNames. A cluster that's gone, or an
+// error resolving its client/GVR, is logged and skipped rather than
+// blocking the rest.
+func (r *FederatedObjectReconciler) deleteFromClusters(ctx context.Context, fedObject *federationv1.FederatedObject, template *unstructured.Unstructured, overrides []federationv1.ClusterOverride, gvk schema.GroupVersionKind, namespace string, clusterNames []string) {
+	for _, clusterName := range clusterNames {
+		cluster := &federationv1.FederatedCluster{}
+		key := client.ObjectKey{Namespace: fedObject.Namespace, Name: clusterName}
+		if err := r.Get(ctx, key, cluster); err != nil {
+			if !apierrors.IsNotFound(err) {
+				r.Log.Info("Failed to get cluster to delete propagated object", "cluster", clusterName, "error", err.Error())
+			}
+			continue
+		}
+
+		manifest, err := applyOverrides(template, overrides, clusterName)
+		if err != nil {
+			r.Log.Info("Failed to build manifest to delete", "cluster", clusterName, "error", err.Error())
+			continue
+		}
+
+		gvr, err := resolveGVR(gvk, cluster)
+		if err != nil {
+			r.Log.Info("Failed to resolve GVR to delete", "cluster", clusterName, "error", err.Error())
+			continue
+		}
+
+		managed, err := r.clusterManager().EnsureCluster(ctx, cluster)
+		if err != nil {
+			r.Log.Info("Failed to ensure cluster client to delete", "cluster", clusterName, "error", err.Error())
+			continue
+		}
+
+		if err := managed.ClusterClient().DeleteObject(ctx, gvr, namespace, manifest.GetName()); err != nil {
+			r.Log.Info("Failed to delete propagated object from cluster", "cluster", clusterName, "error", err.Error())
+		}
+	}
+}
+
+// resolveGVR maps gvk to the GroupVersionResource it is actually served
+// under in cluster, using the APIEnablements CollectAPIEnablements last
+// recorded on its status instead of guessing a plural from the Kind (which
+// silently mis-resolves irregular plurals, e.g. Endpoints).
+// If the cluster hasn't completed a discovery pass yet, it falls back to
+// the same best-effort guess used previously rather than blocking
+// propagation on a brand-new cluster.
+func resolveGVR(gvk schema.GroupVersionKind, cluster *federationv1.FederatedCluster) (schema.GroupVersionResource, error) {
+	if len(cluster.Status.APIEnablements) == 0 {
+		gvr, _ := meta.UnsafeGuessKindToResource(gvk)
+		return gvr, nil
+	}
+
+	groupVersion := gvk.GroupVersion().String()
+	for _, enablement := range cluster.Status.APIEnablements {
+		if enablement.GroupVersion != groupVersion {
+			continue
+		}
+		for _, resource := range enablement.Resources {
+			if resource.Kind == gvk.Kind {
+				return schema.GroupVersionResource{Group: gvk.Group, Version: gvk.Version, Resource: resource.Name}, nil
+			}
+		}
+		return schema.GroupVersionResource{}, fmt.Errorf("cluster %q does not serve kind %q in group version %q", cluster.Name, gvk.Kind, groupVersion)
+	}
+	return schema.GroupVersionResource{}, fmt.Errorf("cluster %q has not discovered group version %q", cluster.Name, groupVersion)
+}
+
+func failedStatus(clusterName string, err error) federationv1.PropagatedClusterStatus {
+	reason := err.Error()
+	return federationv1.PropagatedClusterStatus{
+		Cluster: clusterName,
+		Status:  common.ClusterPropagationFailed,
+		Reason:  &reason,
+	}
+}
+
+// mergeClusterStatusTimes sets each entry in next to now, unless it has the
+// same Status/Reason as its counterpart in previous, in which case the old
+// LastUpdateTime is kept. Without this, Reconcile would stamp a fresh
+// LastUpdateTime (and so call Status().Update) on every single pass, and
+// since SetupWithManager has no change-filter predicate, that status write
+// would immediately re-trigger Reconcile — an unbounded loop that
+// re-applies the object to every member cluster forever.
+func mergeClusterStatusTimes(previous, next []federationv1.PropagatedClusterStatus) []federationv1.PropagatedClusterStatus {
+	previousByCluster := make(map[string]federationv1.PropagatedClusterStatus, len(previous))
+	for _, status := range previous {
+		previousByCluster[status.Cluster] = status
+	}
+
+	now := metav1.Now()
+	for i := range next {
+		old, ok := previousByCluster[next[i].Cluster]
+		if ok && old.Status == next[i].Status && reasonString(old.Reason) == reasonString(next[i].Reason) {
+			next[i].LastUpdateTime = old.LastUpdateTime
+			continue
+		}
+		next[i].LastUpdateTime = now
+	}
+	return next
+}
+
+// reasonString dereferences reason, treating nil the same as an empty
+// string so it can be compared directly.
+func reasonString(reason *string) string {
+	if reason == nil {
+		return ""
+	}
+	return *reason
+}
+
+// resolveTargetClusters returns the FederatedClusters matched by Placement,
+// combining any explicit Clusters list, ClusterSelector, and referenced
+// PropagationPolicy's selector/affinity terms, plus that PropagationPolicy's
+// spec (nil if Placement.PolicyRef is unset) so Reconcile can read its
+// PlacementMode/MinReplicas/MaxReplicas. It does not apply taint filtering:
+// that's left to filterByTaints, which needs to know which clusters the
+// object was already applied to before it can tell a NoSchedule taint
+// (blocks new placement only) apart from a NoExecute one (evicts existing
+// placement too).
+func (r *FederatedObjectReconciler) resolveTargetClusters(ctx context.Context, fedObject *federationv1.FederatedObject) ([]federationv1.FederatedCluster, *federationv1.PropagationPolicySpec, error) {
+	placement := fedObject.Spec.Placement
+
+	var selector, placementSelector labels.Selector
+	var err error
+	var policySpec *federationv1.PropagationPolicySpec
+	if placement.PolicyRef != nil {
+		policy := &federationv1.PropagationPolicy{}
+		key := client.ObjectKey{Namespace: fedObject.Namespace, Name: placement.PolicyRef.Name}
+		if err := r.Get(ctx, key, policy); err != nil {
+			return nil, nil, errors.Wrapf(err, "failed to get propagation policy %q", placement.PolicyRef.Name)
+		}
+		policySpec = &policy.Spec
+		if selector, err = selectorForPolicy(policy.Spec); err != nil {
+			return nil, nil, err
+		}
+	}
+	if placement.ClusterSelector != nil {
+		if placementSelector, err = metav1.LabelSelectorAsSelector(placement.ClusterSelector); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	explicit := make(map[string]bool, len(placement.Clusters))
+	for _, c := range placement.Clusters {
+		explicit[c.Name] = true
+	}
+
+	if selector == nil && placementSelector == nil && len(explicit) == 0 {
+		return nil, policySpec, nil
+	}
+
+	clusterList := &federationv1.FederatedClusterList{}
+	if err := r.List(ctx, clusterList, client.InNamespace(fedObject.Namespace)); err != nil {
+		return nil, nil, err
+	}
+
+	var matched []federationv1.FederatedCluster
+	for _, cluster := range clusterList.Items {
+		clusterLabels := labels.Set(cluster.Labels)
+		if !explicit[cluster.Name] && !(selector != nil && selector.Matches(clusterLabels)) && !(placementSelector != nil && placementSelector.Matches(clusterLabels)) {
+			continue
+		}
+		matched = append(matched, cluster)
+	}
+	return matched, policySpec, nil
+}
+
+// divideReplicaOverrides splits template's spec.replicas as evenly as
+// possible across targetClusters (any remainder going to the earliest
+// clusters by index), clamped to [minReplicas, maxReplicas], and returns one
+// synthesized ClusterOverride per cluster setting spec.replicas to its
+// share. A cluster already named in userOverrides is skipped: applyOverrides
+// only honours the first ClusterOverride entry for a given cluster name, so
+// a user-authored override always wins over a synthesized one placed after
+// it. Returns nil if template has no spec.replicas to divide.
+func divideReplicaOverrides(template *unstructured.Unstructured, targetClusters []federationv1.FederatedCluster, minReplicas, maxReplicas *int32, userOverrides []federationv1.ClusterOverride) []federationv1.ClusterOverride {
+	total, found, err := unstructured.NestedInt64(template.Object, "spec", "replicas")
+	if err != nil || !found {
+		return nil
+	}
+
+	overridden := make(map[string]bool, len(userOverrides))
+	for _, override := range userOverrides {
+		overridden[override.ClusterName] = true
+	}
+
+	var eligible []string
+	for _, cluster := range targetClusters {
+		if !overridden[cluster.Name] {
+			eligible = append(eligible, cluster.Name)
+		}
+	}
+	if len(eligible) == 0 {
+		return nil
+	}
+
+	base := total / int64(len(eligible))
+	remainder := total % int64(len(eligible))
+
+	synthesized := make([]federationv1.ClusterOverride, 0, len(eligible))
+	for i, clusterName := range eligible {
+		share := base
+		if int64(i) < remainder {
+			share++
+		}
+		if minReplicas != nil && share < int64(*minReplicas) {
+			share = int64(*minReplicas)
+		}
+		if maxReplicas != nil && share > int64(*maxReplicas) {
+			share = int64(*maxReplicas)
+		}
+
+		value := apiextensionsv1.JSON{Raw: []byte(strconv.FormatInt(share, 10))}
+		synthesized = append(synthesized, federationv1.ClusterOverride{
+			ClusterName: clusterName,
+			Patches: []federationv1.OverridePatch{
+				{Op: "replace", Path: "/spec/replicas", Value: &value},
+			},
+		})
+	}
+	return synthesized
+}
+
+// filterByTaints narrows matchedClusters to the ones actually eligible for
+// placement this reconcile. A cluster tainted NoExecute (and not
+// tolerated) is dropped whether or not the object is already placed there;
+// a cluster tainted only NoSchedule blocks *new* placement but, per
+// TaintEffectNoSchedule's documented "without disturbing ones already
+// there" semantics, is left alone for a cluster the object was already
+// successfully applied to. PreferNoSchedule is a soft signal for the
+// scheduler, not something this enforces.
+func filterByTaints(matchedClusters []federationv1.FederatedCluster, tolerations []federationv1.Toleration, previouslyApplied map[string]bool) []federationv1.FederatedCluster {
+	var targets []federationv1.FederatedCluster
+	for _, cluster := range matchedClusters {
+		if _, evicted := taintutil.FindUntoleratedTaint(cluster.Spec.Taints, tolerations, blocksExistingPlacement); evicted {
+			continue
+		}
+		if !previouslyApplied[cluster.Name] {
+			if _, blocked := taintutil.FindUntoleratedTaint(cluster.Spec.Taints, tolerations, blocksNewPlacement); blocked {
+				continue
+			}
+		}
+		targets = append(targets, cluster)
+	}
+	return targets
+}
+
+// blocksNewPlacement reports whether taint should keep a FederatedObject
+// from being newly placed in the cluster it's on, absent a matching
+// Toleration.
+func blocksNewPlacement(taint *federationv1.Taint) bool {
+	return taint.Effect == federationv1.TaintEffectNoSchedule || taint.Effect == federationv1.TaintEffectNoExecute
+}
+
+// blocksExistingPlacement reports whether taint should evict a
+// FederatedObject already placed in the cluster it's on, absent a matching
+// Toleration.
+func blocksExistingPlacement(taint *federationv1.Taint) bool {
+	return taint.Effect == federationv1.TaintEffectNoExecute
+}
+
+func selectorForPolicy(spec federationv1.PropagationPolicySpec) (labels.Selector, error) {
+	var selector labels.Selector
+	var err error
+	if spec.ClusterSelector != nil {
+		selector, err = metav1.LabelSelectorAsSelector(spec.ClusterSelector)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		selector = labels.Everything()
+	}
+
+	requirements, selectable := selector.Requirements()
+	if !selectable {
+		return selector, nil
+	}
+	for _, affinity := range spec.ClusterAffinity {
+		requirement, err := labels.NewRequirement(affinity.Key, "in", affinity.Values)
+		if err != nil {
+			return nil, err
+		}
+		requirements = append(requirements, *requirement)
+	}
+	return labels.NewSelector().Add(requirements...), nil
+}
+
+// applyOverrides returns a deep copy of template with the JSON-patch
+// operations scoped to clusterName applied on top.
+func applyOverrides(template *unstructured.Unstructured, overrides []federationv1.ClusterOverride, clusterName string) (*unstructured.Unstructured, error) {
+	result := template.DeepCopy()
+
+	var patches []federationv1.OverridePatch
+	for _, override := range overrides {
+		if override.ClusterName == clusterName {
+			patches = override.Patches
+			break
+		}
+	}
+	if len(patches) == 0 {
+		return result, nil
+	}
+
+	patchJSON, err := json.Marshal(patches)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to marshal overrides for cluster %q", clusterName)
+	}
+	patch, err := jsonpatch.DecodePatch(patchJSON)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid overrides for cluster %q", clusterName)
+	}
+
+	docJSON, err := json.Marshal(result.Object)
+	if err != nil {
+		return nil, err
+	}
+	patchedJSON, err := patch.Apply(docJSON)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to apply overrides for cluster %q", clusterName)
+	}
+
+	patched := &unstructured.Unstructured{}
+	if err := json.Unmarshal(patchedJSON, patched); err != nil {
+		return nil, err
+	}
+	if patched.GetName() == "" {
+		return nil, fmt.Errorf("overrides for cluster %q produced a manifest without a name", clusterName)
+	}
+	return patched, nil
+}
+
+// federatedClusterToObjects requeues every FederatedObject in a changed
+// FederatedCluster's namespace, since the cluster may newly match (or stop
+// matching) an object's Placement.ClusterSelector/PolicyRef, or may have
+// just been tainted/untainted.
+func (r *FederatedObjectReconciler) federatedClusterToObjects(cluster client.Object) []ctrl.Request {
+	objectList := &federationv1.FederatedObjectList{}
+	if err := r.List(context.Background(), objectList, client.InNamespace(cluster.GetNamespace())); err != nil {
+		return nil
+	}
+
+	requests := make([]ctrl.Request, 0, len(objectList.Items))
+	for _, object := range objectList.Items {
+		requests = append(requests, ctrl.Request{
+			NamespacedName: client.ObjectKeyFromObject(&object),
+		})
+	}
+	return requests
+}
+
+// propagationPolicyToObjects requeues every FederatedObject in a changed
+// PropagationPolicy's namespace that references it via Placement.PolicyRef.
+func (r *FederatedObjectReconciler) propagationPolicyToObjects(policy client.Object) []ctrl.Request {
+	objectList := &federationv1.FederatedObjectList{}
+	if err := r.List(context.Background(), objectList, client.InNamespace(policy.GetNamespace())); err != nil {
+		return nil
+	}
+
+	var requests []ctrl.Request
+	for _, object := range objectList.Items {
+		if object.Spec.Placement.PolicyRef != nil && object.Spec.Placement.PolicyRef.Name == policy.GetName() {
+			requests = append(requests, ctrl.Request{
+				NamespacedName: client.ObjectKeyFromObject(&object),
+			})
+		}
+	}
+	return requests
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *FederatedObjectReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&federationv1.FederatedObject{}).
+		Watches(
+			&source.Kind{Type: &federationv1.FederatedCluster{}},
+			handler.EnqueueRequestsFromMapFunc(r.federatedClusterToObjects),
+		).
+		Watches(
+			&source.Kind{Type: &federationv1.PropagationPolicy{}},
+			handler.EnqueueRequestsFromMapFunc(r.propagationPolicyToObjects),
+		).
+		Complete(r)
+}
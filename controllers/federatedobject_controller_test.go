@@ -0,0 +1,179 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"encoding/json"
+	"testing"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+
+	federationv1 "github.com/cloudfunny/kubefederation/api/v1"
+)
+
+func rawJSON(t *testing.T, v interface{}) *apiextensionsv1.JSON {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("failed to marshal %v: %v", v, err)
+	}
+	return &apiextensionsv1.JSON{Raw: data}
+}
+
+func TestApplyOverrides(t *testing.T) {
+	template := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "apps/v1",
+			"kind":       "Deployment",
+			"metadata":   map[string]interface{}{"name": "web"},
+			"spec":       map[string]interface{}{"replicas": int64(1)},
+		},
+	}
+
+	t.Run("no matching override returns an unmodified copy", func(t *testing.T) {
+		result, err := applyOverrides(template, nil, "cluster-a")
+		if err != nil {
+			t.Fatalf("applyOverrides() error = %v", err)
+		}
+		if result == template {
+			t.Error("expected a copy, got the same pointer")
+		}
+		replicas, _, _ := unstructured.NestedInt64(result.Object, "spec", "replicas")
+		if replicas != 1 {
+			t.Errorf("replicas = %d, want 1", replicas)
+		}
+	})
+
+	t.Run("matching override is applied", func(t *testing.T) {
+		overrides := []federationv1.ClusterOverride{
+			{
+				ClusterName: "cluster-a",
+				Patches: []federationv1.OverridePatch{
+					{Op: "replace", Path: "/spec/replicas", Value: rawJSON(t, 3)},
+				},
+			},
+		}
+		result, err := applyOverrides(template, overrides, "cluster-a")
+		if err != nil {
+			t.Fatalf("applyOverrides() error = %v", err)
+		}
+		replicas, _, _ := unstructured.NestedInt64(result.Object, "spec", "replicas")
+		if replicas != 3 {
+			t.Errorf("replicas = %d, want 3", replicas)
+		}
+	})
+
+	t.Run("override for a different cluster is ignored", func(t *testing.T) {
+		overrides := []federationv1.ClusterOverride{
+			{
+				ClusterName: "cluster-b",
+				Patches: []federationv1.OverridePatch{
+					{Op: "replace", Path: "/spec/replicas", Value: rawJSON(t, 3)},
+				},
+			},
+		}
+		result, err := applyOverrides(template, overrides, "cluster-a")
+		if err != nil {
+			t.Fatalf("applyOverrides() error = %v", err)
+		}
+		replicas, _, _ := unstructured.NestedInt64(result.Object, "spec", "replicas")
+		if replicas != 1 {
+			t.Errorf("replicas = %d, want 1", replicas)
+		}
+	})
+
+	t.Run("override removing the name errors", func(t *testing.T) {
+		overrides := []federationv1.ClusterOverride{
+			{
+				ClusterName: "cluster-a",
+				Patches: []federationv1.OverridePatch{
+					{Op: "remove", Path: "/metadata/name"},
+				},
+			},
+		}
+		if _, err := applyOverrides(template, overrides, "cluster-a"); err == nil {
+			t.Error("expected an error for a manifest without a name")
+		}
+	})
+
+	t.Run("invalid patch op errors", func(t *testing.T) {
+		overrides := []federationv1.ClusterOverride{
+			{
+				ClusterName: "cluster-a",
+				Patches: []federationv1.OverridePatch{
+					{Op: "not-a-real-op", Path: "/spec/replicas", Value: rawJSON(t, 3)},
+				},
+			},
+		}
+		if _, err := applyOverrides(template, overrides, "cluster-a"); err == nil {
+			t.Error("expected an error for an invalid patch operation")
+		}
+	})
+}
+
+func TestSelectorForPolicy(t *testing.T) {
+	t.Run("no ClusterSelector matches everything", func(t *testing.T) {
+		selector, err := selectorForPolicy(federationv1.PropagationPolicySpec{})
+		if err != nil {
+			t.Fatalf("selectorForPolicy() error = %v", err)
+		}
+		if !selector.Matches(labels.Set(map[string]string{"any": "label"})) {
+			t.Error("expected the empty selector to match any labels")
+		}
+	})
+
+	t.Run("ClusterSelector alone", func(t *testing.T) {
+		spec := federationv1.PropagationPolicySpec{
+			ClusterSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"env": "prod"}},
+		}
+		selector, err := selectorForPolicy(spec)
+		if err != nil {
+			t.Fatalf("selectorForPolicy() error = %v", err)
+		}
+		if !selector.Matches(labels.Set(map[string]string{"env": "prod"})) {
+			t.Error("expected selector to match env=prod")
+		}
+		if selector.Matches(labels.Set(map[string]string{"env": "dev"})) {
+			t.Error("expected selector not to match env=dev")
+		}
+	})
+
+	t.Run("ClusterAffinity terms are ANDed with ClusterSelector", func(t *testing.T) {
+		spec := federationv1.PropagationPolicySpec{
+			ClusterSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"env": "prod"}},
+			ClusterAffinity: []federationv1.ClusterAffinityTerm{
+				{Key: "region", Values: []string{"us-east", "us-west"}},
+			},
+		}
+		selector, err := selectorForPolicy(spec)
+		if err != nil {
+			t.Fatalf("selectorForPolicy() error = %v", err)
+		}
+		if !selector.Matches(labels.Set(map[string]string{"env": "prod", "region": "us-west"})) {
+			t.Error("expected selector to match env=prod,region=us-west")
+		}
+		if selector.Matches(labels.Set(map[string]string{"env": "prod", "region": "eu-west"})) {
+			t.Error("expected selector not to match a region outside ClusterAffinity values")
+		}
+		if selector.Matches(labels.Set(map[string]string{"region": "us-west"})) {
+			t.Error("expected selector not to match when ClusterSelector's own requirement fails")
+		}
+	})
+}
@@ -0,0 +1,71 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package taintutil matches FederatedCluster Taints against FederatedObject
+// Tolerations, so the propagation subsystem can decide whether a given
+// cluster is a valid placement target.
+package taintutil
+
+import (
+	federationv1 "github.com/cloudfunny/kubefederation/api/v1"
+)
+
+// TolerationToleratesTaint reports whether toleration tolerates taint.
+func TolerationToleratesTaint(toleration *federationv1.Toleration, taint *federationv1.Taint) bool {
+	if toleration.Effect != "" && toleration.Effect != taint.Effect {
+		return false
+	}
+
+	if toleration.Key != "" && toleration.Key != taint.Key {
+		return false
+	}
+
+	switch toleration.Operator {
+	case federationv1.TolerationOpExists:
+		return true
+	case "", federationv1.TolerationOpEqual:
+		return toleration.Value == taint.Value
+	default:
+		return false
+	}
+}
+
+// TolerationsTolerateTaint reports whether any of tolerations tolerates
+// taint.
+func TolerationsTolerateTaint(tolerations []federationv1.Toleration, taint *federationv1.Taint) bool {
+	for i := range tolerations {
+		if TolerationToleratesTaint(&tolerations[i], taint) {
+			return true
+		}
+	}
+	return false
+}
+
+// FindUntoleratedTaint returns the first taint in taints that none of
+// tolerations tolerates, matching only taints for which filter returns true
+// (pass a nil filter to consider every taint).
+func FindUntoleratedTaint(taints []federationv1.Taint, tolerations []federationv1.Toleration, filter func(*federationv1.Taint) bool) (federationv1.Taint, bool) {
+	for i := range taints {
+		taint := &taints[i]
+		if filter != nil && !filter(taint) {
+			continue
+		}
+		if !TolerationsTolerateTaint(tolerations, taint) {
+			return *taint, true
+		}
+	}
+	return federationv1.Taint{}, false
+}
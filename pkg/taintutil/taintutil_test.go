@@ -0,0 +1,117 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package taintutil
+
+import (
+	"testing"
+
+	federationv1 "github.com/cloudfunny/kubefederation/api/v1"
+)
+
+func TestTolerationToleratesTaint(t *testing.T) {
+	taint := federationv1.Taint{Key: "federation.example.com/not-ready", Value: "foo", Effect: federationv1.TaintEffectNoSchedule}
+
+	cases := []struct {
+		name       string
+		toleration federationv1.Toleration
+		want       bool
+	}{
+		{
+			name:       "exists matches any value",
+			toleration: federationv1.Toleration{Key: "federation.example.com/not-ready", Operator: federationv1.TolerationOpExists},
+			want:       true,
+		},
+		{
+			name:       "equal with matching value",
+			toleration: federationv1.Toleration{Key: "federation.example.com/not-ready", Operator: federationv1.TolerationOpEqual, Value: "foo"},
+			want:       true,
+		},
+		{
+			name:       "equal with mismatched value",
+			toleration: federationv1.Toleration{Key: "federation.example.com/not-ready", Operator: federationv1.TolerationOpEqual, Value: "bar"},
+			want:       false,
+		},
+		{
+			name:       "mismatched key",
+			toleration: federationv1.Toleration{Key: "other-key", Operator: federationv1.TolerationOpExists},
+			want:       false,
+		},
+		{
+			name:       "mismatched effect",
+			toleration: federationv1.Toleration{Key: "federation.example.com/not-ready", Operator: federationv1.TolerationOpExists, Effect: federationv1.TaintEffectNoExecute},
+			want:       false,
+		},
+		{
+			name:       "empty key with exists matches any key",
+			toleration: federationv1.Toleration{Operator: federationv1.TolerationOpExists},
+			want:       true,
+		},
+		{
+			name:       "default operator behaves as equal",
+			toleration: federationv1.Toleration{Key: "federation.example.com/not-ready", Value: "foo"},
+			want:       true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := TolerationToleratesTaint(&tc.toleration, &taint); got != tc.want {
+				t.Errorf("TolerationToleratesTaint() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestFindUntoleratedTaint(t *testing.T) {
+	taints := []federationv1.Taint{
+		{Key: "federation.example.com/not-ready", Effect: federationv1.TaintEffectNoSchedule},
+		{Key: "federation.example.com/unreachable", Effect: federationv1.TaintEffectNoExecute},
+	}
+
+	t.Run("no tolerations returns first matching taint", func(t *testing.T) {
+		taint, found := FindUntoleratedTaint(taints, nil, nil)
+		if !found {
+			t.Fatal("expected an untolerated taint")
+		}
+		if taint.Key != taints[0].Key {
+			t.Errorf("got taint %q, want %q", taint.Key, taints[0].Key)
+		}
+	})
+
+	t.Run("tolerations covering both taints finds none", func(t *testing.T) {
+		tolerations := []federationv1.Toleration{
+			{Key: "federation.example.com/not-ready", Operator: federationv1.TolerationOpExists},
+			{Key: "federation.example.com/unreachable", Operator: federationv1.TolerationOpExists},
+		}
+		if _, found := FindUntoleratedTaint(taints, tolerations, nil); found {
+			t.Error("expected no untolerated taint")
+		}
+	})
+
+	t.Run("filter skips taints it excludes", func(t *testing.T) {
+		onlyNoExecute := func(taint *federationv1.Taint) bool {
+			return taint.Effect == federationv1.TaintEffectNoExecute
+		}
+		taint, found := FindUntoleratedTaint(taints, nil, onlyNoExecute)
+		if !found {
+			t.Fatal("expected an untolerated taint")
+		}
+		if taint.Key != "federation.example.com/unreachable" {
+			t.Errorf("got taint %q, want federation.example.com/unreachable", taint.Key)
+		}
+	})
+}